@@ -0,0 +1,534 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// AvroOptions represents the options of FromAvroSchema function.
+type AvroOptions struct {
+	// rule of ordering properties, DocumentOrder orders properties by the order of record fields
+	Order PropertyOrder
+	// use big-endian number types instead of little-endian types
+	BigEndian bool
+}
+
+// avroTypeAttr is the attribute of Avro schema which specifies the jsonpack type explicitly,
+// likes {"type": "int", "jsonpackType": "uint16le"}.
+const avroTypeAttr = "jsonpackType"
+
+// the Avro primitive types which map to jsonpack types, the number types have no byte order suffix
+var avroPrimitiveTypes = map[string]string{
+	"boolean": "boolean",
+	"int":     "int32",
+	"long":    "int64",
+	"float":   "float32",
+	"double":  "float64",
+	"string":  "string",
+}
+
+/*
+FromAvroSchema converts Avro schema document doc to schema definition.
+
+The Avro types are converted as:
+
+* record to object type, the fields are ordered by opts.Order.
+
+* array to array type.
+
+* boolean and string to the same types, int to int32, long to int64, float to float32, and double to float64.
+
+* enum to string type, the JSON form of enum symbol is string.
+
+* bytes and fixed to array of uint8 type.
+
+* union of null and another type to the other type, and it's marked as optional.
+
+* logical types to the types of their underlying types, likes "timestamp-millis" to int64, "uuid"
+to string, and "decimal" to array of uint8.
+
+The named types are resolved with namespaces, and the "jsonpackType" attribute specifies jsonpack
+type explicitly, likes {"type": "int", "jsonpackType": "uint16le"}.
+
+The conversions of records, arrays, boolean, string, int, long, float, double and unions with null
+are lossless. The enum, bytes, fixed and logical types lose their constraints, likes the symbols of enum
+and the size of fixed.
+
+It returns *ConvertError error with the property path if doc has constructs that can't be represented by
+schema definition, likes maps, unions of multiple non-null types, recursive types, and the names which
+aren't valid Avro names.
+
+Example:
+	schDef, err := jsonpack.FromAvroSchema(doc, jsonpack.AvroOptions{})
+	sch, err := jsonPack.AddSchema("Event", *schDef)
+*/
+func FromAvroSchema(doc []byte, opts AvroOptions) (*SchemaDef, error) {
+	root, err := parseOrderedJSON(doc)
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+
+	c := avroConverter{
+		opts:      opts,
+		named:     make(map[string]*orderedObject),
+		namespace: make(map[string]string),
+		resolving: make(map[string]bool),
+	}
+	schDef, _, err := c.convert(root, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if schDef.Type != "object" && schDef.Type != "array" {
+		return nil, errors.WithStack(&ConvertError{"", errors.Errorf("top-level type needs to be record or array, got '%s'", schDef.Type)})
+	}
+	return schDef, nil
+}
+
+// avroConverter converts Avro schema to schema definition.
+type avroConverter struct {
+	opts AvroOptions
+	// named types by full name
+	named map[string]*orderedObject
+	// namespace of named types by full name
+	namespace map[string]string
+	// named types which are being converted, for detecting recursive types
+	resolving map[string]bool
+}
+
+func (c *avroConverter) errorf(path string, format string, args ...interface{}) error {
+	return errors.WithStack(&ConvertError{path, errors.Errorf(format, args...)})
+}
+
+// numberType returns jsonpack number type with byte order suffix.
+func (c *avroConverter) numberType(typ string) string {
+	if c.opts.BigEndian {
+		return typ + "be"
+	}
+	return typ + "le"
+}
+
+// convert converts Avro schema v in namespace to schema definition, and reports whether the schema
+// is a union with null.
+func (c *avroConverter) convert(v interface{}, namespace string, path string) (*SchemaDef, bool, error) {
+	switch v := v.(type) {
+	case string:
+		schDef, err := c.convertName(v, namespace, path)
+		return schDef, false, err
+
+	case []interface{}:
+		return c.convertUnion(v, namespace, path)
+
+	case *orderedObject:
+		schDef, err := c.convertObject(v, namespace, path)
+		return schDef, false, err
+	}
+	return nil, false, c.errorf(path, "schema needs to be a string, an array or an object")
+}
+
+// convertName converts primitive type or named type of name.
+func (c *avroConverter) convertName(name string, namespace string, path string) (*SchemaDef, error) {
+	switch name {
+	case "null":
+		return nil, c.errorf(path, "null type is only supported in union")
+	case "bytes":
+		return &SchemaDef{Type: "array", Items: &SchemaDef{Type: "uint8"}}, nil
+	case "boolean", "string":
+		return &SchemaDef{Type: name}, nil
+	}
+	if typ, ok := avroPrimitiveTypes[name]; ok {
+		return &SchemaDef{Type: c.numberType(typ)}, nil
+	}
+
+	fullName := name
+	if !strings.Contains(name, ".") && namespace != "" {
+		fullName = namespace + "." + name
+	}
+	schema, ok := c.named[fullName]
+	if !ok {
+		if schema, ok = c.named[name]; !ok {
+			return nil, c.errorf(path, "unknown type '%s'", name)
+		}
+		fullName = name
+	}
+	if c.resolving[fullName] {
+		return nil, c.errorf(path, "recursive type '%s' is not supported", fullName)
+	}
+	return c.convertObject(schema, c.namespace[fullName], path)
+}
+
+// convertUnion converts union which has exactly one non-null type.
+func (c *avroConverter) convertUnion(members []interface{}, namespace string, path string) (*SchemaDef, bool, error) {
+	var member interface{}
+	nullable := false
+	for _, m := range members {
+		if name, ok := m.(string); ok && name == "null" {
+			nullable = true
+			continue
+		}
+		if member != nil {
+			return nil, false, c.errorf(path, "union of multiple non-null types is not supported")
+		}
+		member = m
+	}
+	if member == nil {
+		return nil, false, c.errorf(path, "union needs a non-null type")
+	}
+
+	schDef, _, err := c.convert(member, namespace, path)
+	return schDef, nullable, err
+}
+
+// convertObject converts the schema which is represented as JSON object.
+func (c *avroConverter) convertObject(schema *orderedObject, namespace string, path string) (*SchemaDef, error) {
+	typ, ok := schema.values["type"].(string)
+	if !ok {
+		// the type is a nested schema likes {"type": {"type": "array", "items": "int"}}
+		t, exist := schema.get("type")
+		if !exist {
+			return nil, c.errorf(path, "type is not specified")
+		}
+		schDef, nullable, err := c.convert(t, namespace, path)
+		if err == nil && nullable {
+			schDef.Optional = true
+		}
+		return schDef, err
+	}
+
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		fullName, ns, err := c.register(schema, namespace, path)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case "enum":
+			return &SchemaDef{Type: "string"}, nil
+		case "fixed":
+			return &SchemaDef{Type: "array", Items: &SchemaDef{Type: "uint8"}}, nil
+		}
+		c.resolving[fullName] = true
+		defer delete(c.resolving, fullName)
+		return c.convertRecord(schema, ns, path)
+
+	case "array":
+		items, exist := schema.get("items")
+		if !exist {
+			return nil, c.errorf(path, "items of array is not specified")
+		}
+		itemDef, nullable, err := c.convert(items, namespace, path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		if nullable {
+			return nil, c.errorf(path+"[]", "nullable items of array is not supported")
+		}
+		return &SchemaDef{Type: "array", Items: itemDef}, nil
+
+	case "map":
+		return nil, c.errorf(path, "map type is not supported")
+	}
+
+	if explicitType, exist := schema.get(avroTypeAttr); exist {
+		name, _ := explicitType.(string)
+		if _, ok := builtinOpHandlerTypes[strings.ToLower(name)]; !ok {
+			return nil, c.errorf(path, "unknown jsonpack type '%v'", explicitType)
+		}
+		return &SchemaDef{Type: name}, nil
+	}
+	// the logical types are converted to the types of underlying types
+	return c.convertName(typ, namespace, path)
+}
+
+// register registers named type schema, and returns its full name and namespace.
+func (c *avroConverter) register(schema *orderedObject, namespace string, path string) (string, string, error) {
+	name, ok := schema.values["name"].(string)
+	if !ok || name == "" {
+		return "", "", c.errorf(path, "name of named type is not specified")
+	}
+	if ns, ok := schema.values["namespace"].(string); ok {
+		namespace = ns
+	}
+
+	fullName := name
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		namespace = name[:idx]
+	} else if namespace != "" {
+		fullName = namespace + "." + name
+	}
+
+	if !isAvroFullName(fullName) {
+		return "", "", c.errorf(path, "'%s' is not a valid Avro name", fullName)
+	}
+	if registered, exist := c.named[fullName]; exist && registered != schema {
+		return "", "", c.errorf(path, "type '%s' is defined more than once", fullName)
+	}
+	c.named[fullName] = schema
+	c.namespace[fullName] = namespace
+	return fullName, namespace, nil
+}
+
+func (c *avroConverter) convertRecord(schema *orderedObject, namespace string, path string) (*SchemaDef, error) {
+	fields, ok := schema.values["fields"].([]interface{})
+	if !ok {
+		return nil, c.errorf(path, "fields of record needs to be an array")
+	}
+
+	schDef := &SchemaDef{
+		Type:       "object",
+		Properties: make(map[string]*SchemaDef, len(fields)),
+		Order:      make([]string, 0, len(fields)),
+	}
+	for _, f := range fields {
+		field, ok := f.(*orderedObject)
+		if !ok {
+			return nil, c.errorf(path, "field of record needs to be an object")
+		}
+		name, ok := field.values["name"].(string)
+		if !ok || name == "" {
+			return nil, c.errorf(path, "name of field is not specified")
+		}
+		fieldPath := formatPath(path, pathElem{name: name})
+		if !isAvroName(name) {
+			return nil, c.errorf(fieldPath, "'%s' is not a valid Avro name", name)
+		}
+		if _, exist := schDef.Properties[name]; exist {
+			return nil, c.errorf(fieldPath, "field is defined more than once")
+		}
+
+		fieldType, exist := field.get("type")
+		if !exist {
+			return nil, c.errorf(fieldPath, "type of field is not specified")
+		}
+		propDef, nullable, err := c.convert(fieldType, namespace, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		propDef.Optional = propDef.Optional || nullable
+
+		schDef.Properties[name] = propDef
+		schDef.Order = append(schDef.Order, name)
+	}
+	if c.opts.Order == SortedOrder {
+		sort.Strings(schDef.Order)
+	}
+	return schDef, nil
+}
+
+/*
+ToAvroSchema converts schema definition def to Avro schema document, fullName is the full name of
+top-level record likes "example.Info".
+
+The object types are converted to records, the nested records are named by the upper camel case of
+property names, and the optional properties are converted to unions of null and their types with null
+default values.
+
+The number types are converted to the smallest Avro types that hold them, likes uint8 and uint16 types
+to int, and uint32 type to long. The number types which differ from the default types of Avro types have
+"jsonpackType" attribute, likes {"type": "int", "jsonpackType": "uint16le"}, so the document can be
+converted back to the same schema definition by FromAvroSchema function. The uint64 type is converted
+to long, the values which exceed the range of long can't be represented by Avro.
+
+It returns *ConvertError error with the property path if def has unknown types, or the property names
+or fullName aren't valid Avro names, which match [A-Za-z_][A-Za-z0-9_]*.
+*/
+func ToAvroSchema(def *SchemaDef, fullName string) ([]byte, error) {
+	if def == nil {
+		return nil, errors.WithStack(&ConvertError{"", errors.New("schema definition is nil")})
+	}
+
+	if !isAvroFullName(fullName) {
+		return nil, errors.WithStack(&ConvertError{"", errors.Errorf("'%s' is not a valid Avro name", fullName)})
+	}
+
+	w := avroWriter{names: make(map[string]bool)}
+	err := w.writeType(def, fullName, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	err = json.Indent(&out, w.buf.Bytes(), "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	return out.Bytes(), nil
+}
+
+// avroWriter writes Avro schema document of schema definition.
+type avroWriter struct {
+	buf bytes.Buffer
+	// names of records
+	names map[string]bool
+}
+
+// the default jsonpack types of Avro number types, the other types need "jsonpackType" attribute
+var avroDefaultNumberTypes = map[string]string{
+	"int32le":   "int",
+	"int64le":   "long",
+	"float32le": "float",
+	"float64le": "double",
+}
+
+// writeType writes Avro type of def, name is the record name if def is object type.
+func (w *avroWriter) writeType(def *SchemaDef, name string, path string, root bool) error {
+	if def == nil {
+		return errors.WithStack(&ConvertError{path, errors.New("schema definition is nil")})
+	}
+
+	typ := strings.ToLower(def.Type)
+	switch typ {
+	case "object":
+		recordName := name
+		if !root {
+			for w.names[recordName] {
+				recordName += "_"
+			}
+		}
+		w.names[recordName] = true
+		if idx := strings.LastIndexByte(recordName, '.'); idx >= 0 {
+			// the nested records inherit namespace of the top-level record
+			w.names[recordName[idx+1:]] = true
+		}
+
+		w.buf.WriteString(`{"type":"record","name":`)
+		if idx := strings.LastIndexByte(recordName, '.'); idx >= 0 {
+			writeJSONString(&w.buf, recordName[idx+1:])
+			w.buf.WriteString(`,"namespace":`)
+			writeJSONString(&w.buf, recordName[:idx])
+		} else {
+			writeJSONString(&w.buf, recordName)
+		}
+		w.buf.WriteString(`,"fields":[`)
+		for i, propName := range def.Order {
+			propPath := formatPath(path, pathElem{name: propName})
+			prop, ok := def.Properties[propName]
+			if !ok {
+				return errors.WithStack(&ConvertError{propPath, errors.New("property in order doesn't exist")})
+			}
+			if !isAvroName(propName) {
+				return errors.WithStack(&ConvertError{propPath, errors.Errorf("'%s' is not a valid Avro name", propName)})
+			}
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.buf.WriteString(`{"name":`)
+			writeJSONString(&w.buf, propName)
+			w.buf.WriteString(`,"type":`)
+			if prop.Optional {
+				w.buf.WriteString(`["null",`)
+			}
+			err := w.writeType(prop, avroRecordName(propName), propPath, false)
+			if err != nil {
+				return err
+			}
+			if prop.Optional {
+				w.buf.WriteString(`],"default":null`)
+			}
+			w.buf.WriteByte('}')
+		}
+		w.buf.WriteString(`]}`)
+
+	case "array":
+		w.buf.WriteString(`{"type":"array","items":`)
+		err := w.writeType(def.Items, name, path+"[]", false)
+		if err != nil {
+			return err
+		}
+		w.buf.WriteByte('}')
+
+	default:
+		opType, ok := builtinOpHandlerTypes[typ]
+		if !ok {
+			return errors.WithStack(&ConvertError{path, &UnknownTypeError{def.Type}})
+		}
+		typeName := opTypeName(opType)
+		switch {
+		case opType == stringOpType:
+			w.buf.WriteString(`"string"`)
+		case opType == booleanOpType:
+			w.buf.WriteString(`"boolean"`)
+		case avroDefaultNumberTypes[typeName] != "":
+			writeJSONString(&w.buf, avroDefaultNumberTypes[typeName])
+		default:
+			w.buf.WriteString(`{"type":`)
+			writeJSONString(&w.buf, avroNumberType(opType))
+			w.buf.WriteString(`,"` + avroTypeAttr + `":`)
+			writeJSONString(&w.buf, typeName)
+			w.buf.WriteByte('}')
+		}
+	}
+	return nil
+}
+
+// avroNumberType returns the smallest Avro type that holds values of number handler type.
+func avroNumberType(opType opHandlerType) string {
+	switch opType {
+	case int8OpType, int16LEOpType, int16BEOpType, int32LEOpType, int32BEOpType,
+		uint8OpType, uint16LEOpType, uint16BEOpType:
+		return "int"
+	case float32LEOpType, float32BEOpType:
+		return "float"
+	case float64LEOpType, float64BEOpType:
+		return "double"
+	}
+	return "long"
+}
+
+// avroRecordName returns upper camel case of property name as the name of nested record,
+// likes "CurrentStatus" for "current_status", the property name must be a valid Avro name.
+func avroRecordName(propName string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range propName {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	// the name starts with a letter, likes "Record1" for "_1"
+	if sb.Len() == 0 {
+		return "Record"
+	}
+	name := sb.String()
+	if name[0] >= '0' && name[0] <= '9' {
+		return "Record" + name
+	}
+	return name
+}
+
+// isAvroName reports whether s is a valid Avro name, which matches [A-Za-z_][A-Za-z0-9_]*.
+func isAvroName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isAvroFullName reports whether s is a valid Avro full name, which is a name with optional
+// namespace likes "example.Info".
+func isAvroFullName(s string) bool {
+	for _, name := range strings.Split(s, ".") {
+		if !isAvroName(name) {
+			return false
+		}
+	}
+	return true
+}
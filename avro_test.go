@@ -0,0 +1,242 @@
+package jsonpack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestFromAvroSchema(t *testing.T) {
+	doc := []byte(`{
+		"type": "record",
+		"name": "Event",
+		"namespace": "example.events",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "name", "type": "string"},
+			{"name": "score", "type": "float"},
+			{"name": "ratio", "type": "double"},
+			{"name": "count", "type": "int"},
+			{"name": "area", "type": {"type": "int", "jsonpackType": "uint16le"}},
+			{"name": "ok", "type": "boolean"},
+			{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "uuid", "type": {"type": "string", "logicalType": "uuid"}},
+			{"name": "payload", "type": "bytes"},
+			{"name": "hash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+			{"name": "level", "type": {"type": "enum", "name": "Level", "symbols": ["LOW", "HIGH"]}},
+			{"name": "note", "type": ["null", "string"], "default": null},
+			{"name": "tags", "type": {"type": "array", "items": "string"}},
+			{"name": "origin", "type": {
+				"type": "record",
+				"name": "Location",
+				"fields": [{"name": "lat", "type": "double"}, {"name": "lng", "type": "double"}]
+			}},
+			{"name": "stops", "type": {"type": "array", "items": "Location"}},
+			{"name": "target", "type": ["null", "example.events.Location"]}
+		]
+	}`)
+
+	schDef, err := FromAvroSchema(doc, AvroOptions{})
+	if err != nil {
+		t.Fatalf("FromAvroSchema fail, err: %+v", err)
+	}
+
+	locDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"lat": {Type: "float64le"},
+			"lng": {Type: "float64le"},
+		},
+		Order: []string{"lat", "lng"},
+	}
+	targetDef := cloneSchemaDef(locDef)
+	targetDef.Optional = true
+	expDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"id":      {Type: "int64le"},
+			"name":    {Type: "string"},
+			"score":   {Type: "float32le"},
+			"ratio":   {Type: "float64le"},
+			"count":   {Type: "int32le"},
+			"area":    {Type: "uint16le"},
+			"ok":      {Type: "boolean"},
+			"created": {Type: "int64le"},
+			"uuid":    {Type: "string"},
+			"payload": {Type: "array", Items: &SchemaDef{Type: "uint8"}},
+			"hash":    {Type: "array", Items: &SchemaDef{Type: "uint8"}},
+			"level":   {Type: "string"},
+			"note":    {Type: "string", Optional: true},
+			"tags":    {Type: "array", Items: &SchemaDef{Type: "string"}},
+			"origin":  locDef,
+			"stops":   {Type: "array", Items: locDef},
+			"target":  targetDef,
+		},
+		Order: []string{"id", "name", "score", "ratio", "count", "area", "ok", "created", "uuid",
+			"payload", "hash", "level", "note", "tags", "origin", "stops", "target"},
+	}
+	if !reflect.DeepEqual(schDef, expDef) {
+		t.Errorf("FromAvroSchema result: %+v, expect: %+v", schDef, expDef)
+	}
+
+	schDef, err = FromAvroSchema(doc, AvroOptions{Order: SortedOrder, BigEndian: true})
+	if err != nil {
+		t.Fatalf("FromAvroSchema fail, err: %+v", err)
+	}
+	if schDef.Order[0] != "area" || schDef.Properties["id"].Type != "int64be" || schDef.Properties["area"].Type != "uint16le" {
+		t.Errorf("FromAvroSchema with options result: %+v", schDef)
+	}
+
+	invalidDocs := map[string]string{
+		`"string"`: "",
+		`{"type": "record", "name": "R", "fields": [{"name": "m", "type": {"type": "map", "values": "int"}}]}`:        "m",
+		`{"type": "record", "name": "R", "fields": [{"name": "u", "type": ["int", "string"]}]}`:                       "u",
+		`{"type": "record", "name": "R", "fields": [{"name": "next", "type": ["null", "R"]}]}`:                        "next",
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": {"type": "array", "items": "Unknown"}}]}`:   "a[]",
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": {"type": "int", "jsonpackType": "int3"}}]}`: "a",
+		`{"type": "record", "name": "R", "fields": [{"name": "zip-code", "type": "string"}]}`:                         "zip-code",
+		`{"type": "record", "name": "my-record", "fields": [{"name": "a", "type": "string"}]}`:                        "",
+		`{"type": "record", "name": "R", "namespace": "my.1st", "fields": [{"name": "a", "type": "string"}]}`:         "",
+	}
+	for doc, path := range invalidDocs {
+		var convertErr *ConvertError
+		_, err = FromAvroSchema([]byte(doc), AvroOptions{})
+		if !errors.As(err, &convertErr) || convertErr.Path != path {
+			t.Errorf("FromAvroSchema %s should fail at '%s', err: %v", doc, path, err)
+		}
+	}
+}
+
+func TestToAvroSchema(t *testing.T) {
+	schDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name": {Type: "string"},
+			"area": {Type: "uint16le", Optional: true},
+			"id":   {Type: "int64le"},
+			"user": {
+				Type: "object",
+				Properties: map[string]*SchemaDef{
+					"ok": {Type: "bool"},
+				},
+				Order: []string{"ok"},
+			},
+			"info": {
+				Type: "array",
+				Items: &SchemaDef{
+					Type: "object",
+					Properties: map[string]*SchemaDef{
+						"ratio": {Type: "doublebe"},
+					},
+					Order: []string{"ratio"},
+				},
+			},
+		},
+		Order: []string{"name", "area", "id", "user", "info"},
+	}
+
+	doc, err := ToAvroSchema(schDef, "example.Info")
+	if err != nil {
+		t.Fatalf("ToAvroSchema fail, err: %+v", err)
+	}
+	expDoc := `{
+  "type": "record",
+  "name": "Info",
+  "namespace": "example",
+  "fields": [
+    {
+      "name": "name",
+      "type": "string"
+    },
+    {
+      "name": "area",
+      "type": [
+        "null",
+        {
+          "type": "int",
+          "jsonpackType": "uint16le"
+        }
+      ],
+      "default": null
+    },
+    {
+      "name": "id",
+      "type": "long"
+    },
+    {
+      "name": "user",
+      "type": {
+        "type": "record",
+        "name": "User",
+        "fields": [
+          {
+            "name": "ok",
+            "type": "boolean"
+          }
+        ]
+      }
+    },
+    {
+      "name": "info",
+      "type": {
+        "type": "array",
+        "items": {
+          "type": "record",
+          "name": "Info_",
+          "fields": [
+            {
+              "name": "ratio",
+              "type": {
+                "type": "double",
+                "jsonpackType": "float64be"
+              }
+            }
+          ]
+        }
+      }
+    }
+  ]
+}`
+	if string(doc) != expDoc {
+		t.Errorf("ToAvroSchema result:\n%s\nexpect:\n%s", doc, expDoc)
+	}
+
+	// the converted document can be converted back to the same schema definition
+	backDef, err := FromAvroSchema(doc, AvroOptions{})
+	if err != nil {
+		t.Fatalf("FromAvroSchema fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(normalizeSchemaDef(backDef), normalizeSchemaDef(schDef)) {
+		t.Errorf("round-trip schema definition: %+v, expect: %+v", backDef, schDef)
+	}
+
+	var convertErr *ConvertError
+	_, err = ToAvroSchema(&SchemaDef{Type: "object", Properties: map[string]*SchemaDef{"a": {Type: "int3"}}, Order: []string{"a"}}, "R")
+	if !errors.As(err, &convertErr) || convertErr.Path != "a" {
+		t.Errorf("ToAvroSchema with unknown type should fail, err: %v", err)
+	}
+	_, err = ToAvroSchema(&SchemaDef{Type: "object", Properties: map[string]*SchemaDef{"zip-code": {Type: "string"}}, Order: []string{"zip-code"}}, "R")
+	if !errors.As(err, &convertErr) || convertErr.Path != "zip-code" {
+		t.Errorf("ToAvroSchema with invalid property name should fail, err: %v", err)
+	}
+	_, err = ToAvroSchema(&SchemaDef{Type: "object", Properties: map[string]*SchemaDef{"a": {Type: "string"}}, Order: []string{"a"}}, "example.my-info")
+	if !errors.As(err, &convertErr) || convertErr.Path != "" {
+		t.Errorf("ToAvroSchema with invalid record name should fail, err: %v", err)
+	}
+
+	// the nested record of property which starts with digit after removing underscores
+	doc, err = ToAvroSchema(&SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"_1": {Type: "object", Properties: map[string]*SchemaDef{"a": {Type: "string"}}, Order: []string{"a"}},
+		},
+		Order: []string{"_1"},
+	}, "R")
+	if err != nil {
+		t.Fatalf("ToAvroSchema with property '_1' fail, err: %+v", err)
+	}
+	if _, err = FromAvroSchema(doc, AvroOptions{}); err != nil {
+		t.Errorf("FromAvroSchema with nested record of property '_1' fail, err: %+v\n%s", err, doc)
+	}
+}
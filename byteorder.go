@@ -0,0 +1,161 @@
+package jsonpack
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+WithByteOrder returns a new schema instance which has the same schema definition as s,
+except all number types use the byte order specified by byteOrder.
+
+The new schema has the same name and version as s, and it will not be added to schema manager.
+It's usually used with ConvertByteOrder function to convert encoded data between byte orders.
+
+Example:
+	// schema definition of sch uses little-endian number types likes "uint32le"
+	beSch, err := sch.WithByteOrder(jsonpack.BigEndian)
+	// the number types of beSch are big-endian types likes "uint32be"
+	beData, err := jsonpack.ConvertByteOrder(sch, beSch, leData)
+*/
+func (s *Schema) WithByteOrder(byteOrder ByteOrder) (*Schema, error) {
+	schDef, err := s.GetSchemaDef()
+	if err != nil {
+		return nil, err
+	}
+	setSchemaDefByteOrder(schDef, byteOrder)
+
+	schema := newSchema(s.Name, schDef, byteOrder)
+	schema.Version = s.Version
+	err = schema.build()
+	if err != nil {
+		return nil, errors.WithStack(&CompileError{s.Name, err})
+	}
+	return schema, nil
+}
+
+// setSchemaDefByteOrder changes the byte order of all number types in schema definition.
+func setSchemaDefByteOrder(schDef *SchemaDef, byteOrder ByteOrder) {
+	if schDef == nil {
+		return
+	}
+
+	typ := strings.ToLower(schDef.Type)
+	if isBuiltinType(&typ) && (strings.HasSuffix(typ, "le") || strings.HasSuffix(typ, "be")) {
+		if byteOrder == BigEndian {
+			schDef.Type = typ[:len(typ)-2] + "be"
+		} else {
+			schDef.Type = typ[:len(typ)-2] + "le"
+		}
+	}
+
+	setSchemaDefByteOrder(schDef.Items, byteOrder)
+	for _, prop := range schDef.Properties {
+		setSchemaDefByteOrder(prop, byteOrder)
+	}
+}
+
+/*
+ConvertByteOrder rewrites data which encoded by schema from into encoded data of schema to,
+where schema to is the byte order flipped twin of schema from, likes the schema returned by
+Schema.WithByteOrder method.
+
+It reverses the bytes of multi-byte number values and copies other values as is, so it's much
+faster than decoding and encoding data again.
+
+It returns *TranscodeError error if both schemas are not the twins, or the encoded data is invalid.
+Use Transcode function if the schemas have other differences besides byte order.
+*/
+func ConvertByteOrder(from, to *Schema, data []byte) (result []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case string:
+				err = errors.WithStack(&TranscodeError{from.Name, to.Name, errors.New(r)})
+			case error:
+				err = errors.WithStack(&TranscodeError{from.Name, to.Name, r})
+			}
+		}
+	}()
+
+	result = make([]byte, len(data))
+	copy(result, data)
+
+	buf := ibuf.From(result)
+	err = swapByteOrder(buf, from.rootOp, to.rootOp)
+	if err != nil {
+		return nil, errors.WithStack(&TranscodeError{from.Name, to.Name, err})
+	}
+	return result, nil
+}
+
+// swapByteOrder reverses bytes of number values in buf which type differs between fromOp and toOp.
+func swapByteOrder(buf *ibuf.Buffer, fromOp *operation, toOp *operation) error {
+	switch {
+	case fromOp.handlerType == objectOpType && toOp.handlerType == objectOpType:
+		if !sameProperties(fromOp, toOp) {
+			return errors.New("the properties of objects are different")
+		}
+		for i, fromChild := range fromOp.children {
+			err := swapByteOrder(buf, fromChild, toOp.children[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case isArrayOp(fromOp) && isArrayOp(toOp):
+		length, err := readLength(buf)
+		if err != nil {
+			return err
+		}
+		fromItem, toItem := fromOp.children[0], toOp.children[0]
+		// fast path: skip items of the same builtin type
+		if fromItem.handlerType == toItem.handlerType && fromItem.handlerType != objectOpType && !isArrayOp(fromItem) {
+			return skipItems(buf, fromItem, length)
+		}
+		if fromItem.fixedSize != 0 && length > uint64(remainBytes(buf)) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		for i := uint64(0); i < length; i++ {
+			err = swapByteOrder(buf, fromItem, toItem)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case fromOp.handlerType == toOp.handlerType:
+		return skipValue(buf, fromOp)
+
+	case swapEndianType(fromOp.handlerType) == toOp.handlerType:
+		size := fromOp.fixedSize
+		if size > remainBytes(buf) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		data := buf.ReadBytes(size)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		return nil
+
+	default:
+		return errors.Errorf("property '%s' can't be converted from %s type to %s type",
+			toOp.displayName(), opTypeName(fromOp.handlerType), opTypeName(toOp.handlerType))
+	}
+}
+
+// swapEndianType returns the handler type with opposite byte order of typ,
+// or returns typ itself if it doesn't have byte order.
+func swapEndianType(typ opHandlerType) opHandlerType {
+	name := opTypeName(typ)
+	if strings.HasSuffix(name, "le") {
+		return builtinOpHandlerTypes[name[:len(name)-2]+"be"]
+	} else if strings.HasSuffix(name, "be") {
+		return builtinOpHandlerTypes[name[:len(name)-2]+"le"]
+	}
+	return typ
+}
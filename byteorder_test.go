@@ -0,0 +1,46 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestConvertByteOrder(t *testing.T) {
+	leSch := jsonPack.GetSchema("types")
+	beSch, err := leSch.WithByteOrder(BigEndian)
+	if err != nil {
+		t.Fatalf("WithByteOrder fail, err: %+v", err)
+	}
+
+	schDef, _ := beSch.GetSchemaDef()
+	if schDef.Properties["field_int32"].Type != "int32be" || schDef.Properties["field_uint8"].Type != "uint8" {
+		t.Errorf("WithByteOrder type mismatch, got %s and %s",
+			schDef.Properties["field_int32"].Type, schDef.Properties["field_uint8"].Type)
+	}
+
+	beData, err := ConvertByteOrder(leSch, beSch, testdata.TypesExpData)
+	if err != nil {
+		t.Fatalf("ConvertByteOrder fail, err: %+v", err)
+	}
+
+	expData, err := beSch.Encode(testdata.TypesStructData)
+	if err != nil {
+		t.Fatalf("Encode fail, err: %+v", err)
+	}
+	if !compareBytes(t, beData, expData) {
+		t.Errorf("Converted data mismatch")
+	}
+
+	leData, err := ConvertByteOrder(beSch, leSch, beData)
+	if err != nil {
+		t.Fatalf("ConvertByteOrder fail, err: %+v", err)
+	}
+	if !compareBytes(t, leData, testdata.TypesExpData) {
+		t.Errorf("Converted data mismatch")
+	}
+
+	if _, err = ConvertByteOrder(leSch, jsonPack.GetSchema("complex"), testdata.TypesExpData); err == nil {
+		t.Errorf("ConvertByteOrder should fail with different schemas")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+jsonpack-gostruct is a tool to generate Go struct types from schema definition file, it's the opposite of
+jsonpack-parser tool.
+
+Usage: jsonpack-gostruct -i <SCHEMA_FILE> -n <TYPE_NAME> [options]
+
+<SCHEMA_FILE> is the schema definition file in JSON format, or in YAML format if the file extension
+is ".yaml" or ".yml".
+
+<TYPE_NAME> is the name of top-level struct type, the nested struct types are named by <TYPE_NAME>
+followed by the upper camel case of property names.
+
+Optional Options
+-o: output file name for Go source, program will output to stdout if this option not specified
+
+-p: package name of Go source, default is "main"
+
+-h: print help
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/arloliu/jsonpack"
+	"github.com/arloliu/jsonpack/internal/schemafile"
+)
+
+type paramSet struct {
+	schemaFile string
+	typeName   string
+	outputFile string
+	pkgName    string
+}
+
+var params paramSet
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jsonpack-gostruct -i <SCHEMA_FILE> -n <TYPE_NAME> [options]\n")
+		fmt.Fprintf(os.Stderr, "jsonpack-gostruct is a helper tool to generate Go struct types from schema definition file.\n\n")
+		fmt.Fprintf(os.Stderr, "Available options:\n\n")
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&params.schemaFile, "i", "", "the schema definition file in JSON or YAML format")
+	flag.StringVar(&params.typeName, "n", "", "the name of top-level struct type")
+	flag.StringVar(&params.outputFile, "o", "", "output file name for Go source, program will output to stdout if this option not specified")
+	flag.StringVar(&params.pkgName, "p", "main", "package name of Go source")
+
+	flag.Parse()
+
+	if params.schemaFile == "" || params.typeName == "" {
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\n-i and -n options are required.\n")
+		os.Exit(2)
+	}
+}
+
+func main() {
+	schDef, err := schemafile.Load(params.schemaFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load schema definition file %s fail, err: %v\n", params.schemaFile, err)
+		os.Exit(1)
+	}
+
+	// make sure the schema definition is valid before generating struct types
+	_, err = jsonpack.NewJSONPack().AddSchema(params.typeName, *schDef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid schema definition, err: %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := jsonpack.ToGoStruct(schDef, params.typeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Generate Go struct types fail, err: %v\n", err)
+		os.Exit(1)
+	}
+
+	header := fmt.Sprintf("// Code generated by jsonpack-gostruct from %s. DO NOT EDIT.\n\npackage %s\n\n",
+		filepath.Base(params.schemaFile), params.pkgName)
+	src = append([]byte(header), src...)
+
+	if params.outputFile != "" {
+		err = ioutil.WriteFile(params.outputFile, src, 0644) //nolint:gosec
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Write Go source to output file %s fail, err: %v\n", params.outputFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Go struct types have generated to output file %s\n", params.outputFile)
+	} else {
+		fmt.Fprint(os.Stdout, string(src))
+	}
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/arloliu/jsonpack"
+)
+
+const bufferImportPath = "github.com/arloliu/jsonpack/buffer"
+
+// exprString returns Go expression of node which can be referred from the package of struct,
+// and stores the referenced import names and paths into imports.
+//
+// It returns empty string if node is an anonymous type, e.g. struct{...}.
+func (p *packageInfo) exprString(fileInfo *pkgFileInfo, node ast.Node, imports map[string]string) string {
+	switch node := node.(type) {
+	case *ast.Ident:
+		if _, ok := p.buildBultinType(node.Name); ok || p.qualifier == "" {
+			return node.Name
+		}
+		imports[p.qualifier] = p.importPath
+		return p.qualifier + "." + node.Name
+
+	case *ast.BasicLit:
+		return node.Value
+
+	case *ast.SelectorExpr:
+		modName, ok := node.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		if extPkg, ok := fileInfo.imports[modName.Name]; ok {
+			imports[modName.Name] = extPkg.path
+		}
+		return modName.Name + "." + node.Sel.Name
+
+	case *ast.StarExpr:
+		elem := p.exprString(fileInfo, node.X, imports)
+		if elem == "" {
+			return ""
+		}
+		return "*" + elem
+
+	case *ast.ArrayType:
+		elem := p.exprString(fileInfo, node.Elt, imports)
+		if elem == "" {
+			return ""
+		}
+		if node.Len == nil {
+			return "[]" + elem
+		}
+		length := p.exprString(fileInfo, node.Len, imports)
+		if length == "" {
+			return ""
+		}
+		return "[" + length + "]" + elem
+	}
+	return ""
+}
+
+// parsePackageName returns package name of the package in pkgDir.
+func parsePackageName(pkgDir string) (string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pkgDir, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	for name := range pkgs {
+		if !strings.HasSuffix(name, "_test") {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("package not found in %s", pkgDir)
+}
+
+// goGenerator generates Go source of MarshalJSONPack and UnmarshalJSONPack methods from schema definition
+// of struct, the generated methods encode and decode the fields of struct with buffer package directly.
+type goGenerator struct {
+	b       bytes.Buffer
+	imports map[string]string
+	// name of the struct which the methods belong to
+	structName string
+	// sequence number of temporary variables in current method
+	seq int
+}
+
+// generateGoCode returns formatted Go source of MarshalJSONPack and UnmarshalJSONPack methods
+// of structName struct in pkgName package.
+func generateGoCode(pkgName string, structName string, sch *schemaDef) ([]byte, error) {
+	if sch == nil || sch.Type != "object" {
+		return nil, fmt.Errorf("%s is not a struct", structName)
+	}
+
+	fingerprint, err := schemaFingerprint(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	g := goGenerator{imports: map[string]string{"ibuf": bufferImportPath, "fmt": "fmt"}, structName: structName}
+
+	g.line("// JSONPackFingerprint returns the fingerprint of %s schema definition which the methods are generated from,", structName)
+	g.line("// jsonpack uses the methods only if the schema has the same fingerprint.")
+	g.line("func (%s) JSONPackFingerprint() string {", structName)
+	g.line("return %q", fingerprint)
+	g.line("}")
+	g.line("")
+
+	g.line("// MarshalJSONPack encodes v into buf with jsonpack encoding format, and returns the encoded data.")
+	g.line("// The encoded data is the same as the data encoded by jsonpack with %s schema definition,", structName)
+	g.line("// and it returns error if v has nil pointers likes jsonpack.")
+	g.line("func (v %s) MarshalJSONPack(buf []byte) ([]byte, error) {", structName)
+	g.line("b := ibuf.From(buf)")
+	g.seq = 0
+	if err := g.encode(sch, "v", ""); err != nil {
+		return nil, err
+	}
+	g.line("return b.Seal(), nil")
+	g.line("}")
+	g.line("")
+
+	g.line("// UnmarshalJSONPack decodes data which is encoded with %s schema definition into v.", structName)
+	g.line("func (v *%s) UnmarshalJSONPack(data []byte) (err error) {", structName)
+	g.line("defer func() {")
+	g.line("if r := recover(); r != nil {")
+	g.line("err = fmt.Errorf(\"decode %s got error: %%v\", r)", structName)
+	g.line("}")
+	g.line("}()")
+	g.line("")
+	g.line("b := ibuf.From(data)")
+	g.seq = 0
+	if err := g.decode(sch, "v", ""); err != nil {
+		return nil, err
+	}
+	g.line("return nil")
+	g.line("}")
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by jsonpack-parser. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkgName)
+	src.WriteString(g.importDecl())
+	src.Write(g.b.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// schemaFingerprint returns the fingerprint of schema definition sch which is the same as
+// the fingerprint of jsonpack schema.
+func schemaFingerprint(sch *schemaDef) (string, error) {
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return "", err
+	}
+	schDef := jsonpack.SchemaDef{}
+	err = json.Unmarshal(data, &schDef)
+	if err != nil {
+		return "", err
+	}
+	return jsonpack.SchemaFingerprint(&schDef), nil
+}
+
+func (g *goGenerator) importDecl() string {
+	names := make([]string, 0, len(g.imports))
+	for name := range g.imports {
+		names = append(names, name)
+	}
+	// standard packages go first, and separated from other packages by a blank line
+	isStd := func(importPath string) bool {
+		return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := g.imports[names[i]], g.imports[names[j]]
+		if isStd(pi) != isStd(pj) {
+			return isStd(pi)
+		}
+		return pi < pj
+	})
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for i, name := range names {
+		importPath := g.imports[name]
+		if i > 0 && isStd(g.imports[names[i-1]]) && !isStd(importPath) {
+			b.WriteString("\n")
+		}
+		if path.Base(importPath) == name {
+			fmt.Fprintf(&b, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&b, "\t%s %q\n", name, importPath)
+		}
+	}
+	b.WriteString(")\n\n")
+	return b.String()
+}
+
+func (g *goGenerator) line(format string, args ...interface{}) {
+	fmt.Fprintf(&g.b, format, args...)
+	g.b.WriteByte('\n')
+}
+
+// tmp returns a new temporary variable name with prefix.
+func (g *goGenerator) tmp(prefix string) string {
+	name := fmt.Sprintf("%s%d", prefix, g.seq)
+	g.seq++
+	return name
+}
+
+// typeName returns Go type of the value of sch, or the element type if the value is a pointer.
+func (g *goGenerator) typeName(sch *schemaDef, propPath string) (string, error) {
+	typ := sch.goType
+	if sch.goPtr {
+		typ = strings.TrimPrefix(typ, "*")
+	}
+	if typ == "" || strings.HasPrefix(typ, "*") {
+		return "", fmt.Errorf("type of '%s' is anonymous or multi-level pointer, it's not supported", displayPath(propPath))
+	}
+	for name, importPath := range sch.goImports {
+		g.imports[name] = importPath
+	}
+	return typ, nil
+}
+
+// conversion returns the type conversion of the value of sch to baseType or from baseType,
+// it returns empty string if the conversion is unnecessary.
+func (g *goGenerator) conversion(sch *schemaDef, baseType string, propPath string) (string, error) {
+	typ, err := g.typeName(sch, propPath)
+	if err != nil {
+		return "", err
+	}
+	if typ == baseType || (typ == "byte" && baseType == "uint8") {
+		return "", nil
+	}
+	return typ, nil
+}
+
+// encode generates code which encodes the value of expr with schema definition sch.
+func (g *goGenerator) encode(sch *schemaDef, expr string, propPath string) error {
+	if !sch.goPtr {
+		return g.encodeValue(sch, expr, propPath)
+	}
+
+	// returns error if the pointer is nil, the same as the encoder of jsonpack
+	if _, err := g.typeName(sch, propPath); err != nil {
+		return err
+	}
+	g.line("if %s == nil {", expr)
+	g.line("return nil, fmt.Errorf(\"encode %s got nil pointer of '%s'\")", g.structName, displayPath(propPath))
+	g.line("}")
+	return g.encodeValue(sch, derefExpr(sch, expr), propPath)
+}
+
+func (g *goGenerator) encodeValue(sch *schemaDef, expr string, propPath string) error {
+	switch sch.Type {
+	case "object":
+		for _, name := range sch.Order {
+			prop := sch.Properties[name]
+			if err := checkField(sch, prop, propPath, name); err != nil {
+				return err
+			}
+			if err := g.encode(prop, expr+"."+prop.goField, joinPath(propPath, name)); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		g.line("b.WriteVarUint(uint64(len(%s)))", expr)
+		if isByteSlice(sch) {
+			g.line("b.WriteBytes(%s)", expr)
+			return nil
+		}
+		idx := g.tmp("i")
+		g.line("for %s := range %s {", idx, expr)
+		if err := g.encode(sch.Items, paren(expr)+"["+idx+"]", propPath+"[]"); err != nil {
+			return err
+		}
+		g.line("}")
+
+	case "string":
+		conv, err := g.conversion(sch, "string", propPath)
+		if err != nil {
+			return err
+		}
+		// writes the bytes of a local copy instead of Buffer.WriteString, which reinterprets the string
+		// header as a slice header and fails the pointer checks of race build
+		str := g.tmp("s")
+		if conv == "" {
+			g.line("%s := %s", str, expr)
+		} else {
+			g.line("%s := string(%s)", str, expr)
+		}
+		g.line("b.WriteVarUint(uint64(len(%s)))", str)
+		g.line("b.WriteBytes([]byte(%s))", str)
+
+	case "boolean":
+		g.line("if %s {", expr)
+		g.line("b.WriteByte(1)")
+		g.line("} else {")
+		g.line("b.WriteByte(0)")
+		g.line("}")
+
+	default:
+		method, baseType, ok := numberMethod(sch.Type)
+		if !ok {
+			return fmt.Errorf("unknown type '%s' of '%s'", sch.Type, displayPath(propPath))
+		}
+		conv, err := g.conversion(sch, baseType, propPath)
+		if err != nil {
+			return err
+		}
+		switch {
+		case baseType == "int8" || baseType == "uint8":
+			// the same as the encoder of jsonpack, writes single byte directly
+			if conv == "" && baseType == "uint8" {
+				g.line("b.WriteByte(%s)", expr)
+			} else {
+				g.line("b.WriteByte(byte(%s))", expr)
+			}
+		case conv == "":
+			g.line("b.Write%s(%s)", method, expr)
+		default:
+			g.line("b.Write%s(%s(%s))", method, baseType, expr)
+		}
+	}
+	return nil
+}
+
+// decode generates code which decodes the value into expr with schema definition sch.
+func (g *goGenerator) decode(sch *schemaDef, expr string, propPath string) error {
+	if !sch.goPtr {
+		return g.decodeValue(sch, expr, propPath)
+	}
+
+	// reuses existing instance if the pointer isn't nil
+	typ, err := g.typeName(sch, propPath)
+	if err != nil {
+		return err
+	}
+	g.line("if %s == nil {", expr)
+	g.line("%s = new(%s)", expr, typ)
+	g.line("}")
+	return g.decodeValue(sch, derefExpr(sch, expr), propPath)
+}
+
+func (g *goGenerator) decodeValue(sch *schemaDef, expr string, propPath string) error {
+	switch sch.Type {
+	case "object":
+		for _, name := range sch.Order {
+			prop := sch.Properties[name]
+			if err := checkField(sch, prop, propPath, name); err != nil {
+				return err
+			}
+			if err := g.decode(prop, expr+"."+prop.goField, joinPath(propPath, name)); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		length, count, size := g.tmp("l"), g.tmp("c"), g.tmp("n")
+		g.line("%s, %s := b.ReadVarUint()", length, count)
+		// checks the length against the remaining bytes before allocating the slice
+		remain := "uint64(b.Capacity() - b.Offset())"
+		switch itemSize := minEncodedSize(sch.Items); itemSize {
+		case 0:
+			g.line("if %s <= 0 {", count)
+		case 1:
+			g.line("if %s <= 0 || %s > %s {", count, length, remain)
+		default:
+			g.line("if %s <= 0 || %s > %s/%d {", count, length, remain, itemSize)
+		}
+		g.line("return fmt.Errorf(\"decode %s got invalid length of '%s'\")", g.structName, displayPath(propPath))
+		g.line("}")
+		g.line("%s := int(%s)", size, length)
+		// reuses existing slice if its capacity is enough, the items of fixed-size array are decoded in place
+		if !sch.goArray {
+			typ, err := g.typeName(sch, propPath)
+			if err != nil {
+				return err
+			}
+			g.line("if %s != nil && cap(%s) >= %s {", expr, expr, size)
+			g.line("%s = %s[:%s]", expr, paren(expr), size)
+			g.line("} else {")
+			g.line("%s = make(%s, %s)", expr, typ, size)
+			g.line("}")
+			if isByteSlice(sch) {
+				g.line("copy(%s, b.ReadBytes(int64(%s)))", expr, size)
+				return nil
+			}
+		}
+		idx := g.tmp("i")
+		g.line("for %s := 0; %s < %s; %s++ {", idx, idx, size, idx)
+		if err := g.decode(sch.Items, paren(expr)+"["+idx+"]", propPath+"[]"); err != nil {
+			return err
+		}
+		g.line("}")
+
+	case "string":
+		conv, err := g.conversion(sch, "string", propPath)
+		if err != nil {
+			return err
+		}
+		if conv == "" {
+			g.line("%s = b.ReadString()", expr)
+		} else {
+			g.line("%s = %s(b.ReadString())", expr, conv)
+		}
+
+	case "boolean":
+		conv, err := g.conversion(sch, "bool", propPath)
+		if err != nil {
+			return err
+		}
+		if conv == "" {
+			g.line("%s = b.ReadByte() != 0", expr)
+		} else {
+			g.line("%s = %s(b.ReadByte() != 0)", expr, conv)
+		}
+
+	default:
+		method, baseType, ok := numberMethod(sch.Type)
+		if !ok {
+			return fmt.Errorf("unknown type '%s' of '%s'", sch.Type, displayPath(propPath))
+		}
+		conv, err := g.conversion(sch, baseType, propPath)
+		if err != nil {
+			return err
+		}
+		if conv == "" {
+			g.line("%s = b.Read%s()", expr, method)
+		} else {
+			g.line("%s = %s(b.Read%s())", expr, conv, method)
+		}
+	}
+	return nil
+}
+
+// numberMethod returns the suffix of read/write methods of buffer package and Go type of number type,
+// e.g. "Uint32LE" and "uint32" for "uint32le" type.
+func numberMethod(typ string) (string, string, bool) {
+	baseType := typ
+	suffix := ""
+	if strings.HasSuffix(typ, "le") || strings.HasSuffix(typ, "be") {
+		baseType = typ[:len(typ)-2]
+		suffix = strings.ToUpper(typ[len(typ)-2:])
+	}
+	switch baseType {
+	case "int8", "uint8":
+		if suffix != "" {
+			return "", "", false
+		}
+	case "int16", "int32", "int64", "uint16", "uint32", "uint64", "float32", "float64":
+		if suffix == "" {
+			return "", "", false
+		}
+	default:
+		return "", "", false
+	}
+	return strings.ToUpper(baseType[:1]) + baseType[1:] + suffix, baseType, true
+}
+
+// minEncodedSize returns the minimum number of bytes of the value encoded with schema definition sch.
+func minEncodedSize(sch *schemaDef) int {
+	if sch == nil {
+		return 0
+	}
+	switch sch.Type {
+	case "object":
+		size := 0
+		for _, name := range sch.Order {
+			size += minEncodedSize(sch.Properties[name])
+		}
+		return size
+	case "array", "string", "boolean":
+		// the length prefix or the byte of boolean
+		return 1
+	}
+	_, baseType, ok := numberMethod(sch.Type)
+	if !ok {
+		return 0
+	}
+	switch baseType {
+	case "int8", "uint8":
+		return 1
+	case "int16", "uint16":
+		return 2
+	case "int32", "uint32", "float32":
+		return 4
+	}
+	return 8
+}
+
+// isByteSlice reports whether sch is a slice of byte or uint8, which can be read/written at once.
+func isByteSlice(sch *schemaDef) bool {
+	if sch.goPtr || sch.goArray || sch.Items == nil || sch.Items.goPtr {
+		return false
+	}
+	return sch.Items.Type == "uint8" && (sch.Items.goType == "byte" || sch.Items.goType == "uint8")
+}
+
+// paren returns the expression which can be indexed or sliced, e.g. (*p)[i] instead of *p[i].
+func paren(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// derefExpr returns the expression of the value which ptr points to.
+func derefExpr(sch *schemaDef, ptr string) string {
+	// the fields of struct can be accessed by pointer directly
+	if sch.Type == "object" {
+		return ptr
+	}
+	return "*" + ptr
+}
+
+// checkField checks whether the field of property can be accessed by generated code.
+func checkField(sch *schemaDef, prop *schemaDef, propPath string, name string) error {
+	if prop == nil {
+		return fmt.Errorf("property '%s' in order doesn't exist", joinPath(propPath, name))
+	}
+	if prop.goField == "" {
+		return fmt.Errorf("field of property '%s' not found", joinPath(propPath, name))
+	}
+	if sch.goExternal && !ast.IsExported(prop.goField) {
+		return fmt.Errorf("field %s of property '%s' is unexported in imported package", prop.goField, joinPath(propPath, name))
+	}
+	return nil
+}
+
+func joinPath(propPath string, name string) string {
+	if propPath == "" {
+		return name
+	}
+	return propPath + "." + name
+}
+
+func displayPath(propPath string) string {
+	if propPath == "" {
+		return "(root)"
+	}
+	return propPath
+}
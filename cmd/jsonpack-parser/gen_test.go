@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateGoCode generates the methods of Record struct in testdata/gencode, and runs the check
+// program which compares them with the encoding and decoding of jsonpack schema.
+func TestGenerateGoCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip compiling generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	rootDir, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	modDir := t.TempDir()
+	if err = copyDir(filepath.Join("testdata", "gencode"), modDir); err != nil {
+		t.Fatalf("copy testdata fail, err: %v", err)
+	}
+	goMod := fmt.Sprintf("module example.com/gencode\n\ngo 1.15\n\nrequire github.com/arloliu/jsonpack v0.0.0\n\nreplace github.com/arloliu/jsonpack => %s\n", rootDir)
+	if err = ioutil.WriteFile(filepath.Join(modDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := ioutil.ReadFile(filepath.Join(rootDir, "go.sum")) //nolint:gosec
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(modDir, "go.sum"), goSum, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sch, err := parsePackageStruct(modDir, "Record")
+	if err != nil {
+		t.Fatalf("parsePackageStruct fail, err: %v", err)
+	}
+	src, err := generateGoCode("gencode", "Record", sch)
+	if err != nil {
+		t.Fatalf("generateGoCode fail, err: %v", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(modDir, "record_jsonpack.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "run", "./check") //nolint:gosec
+	cmd.Dir = modDir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("check generated code fail, err: %v\n%s\ngenerated source:\n%s", err, out, src)
+	}
+}
+
+// copyDir copies the files in srcDir into dstDir recursively.
+func copyDir(srcDir string, dstDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dstDir, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(dstPath, 0755)
+		}
+		data, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(dstPath, data, 0644) //nolint:gosec
+	})
+}
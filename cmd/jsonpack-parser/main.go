@@ -12,6 +12,10 @@ Optional Options
 
 -p: prettify output JSON text of schema definition with indentation
 
+-g: generate Go source of MarshalJSONPack and UnmarshalJSONPack methods of struct instead of schema definition,
+the methods encode and decode struct without reflection, and they are used by jsonpack automatically when
+the schema has the same fingerprint as the generated JSONPackFingerprint method. The output file should be placed in <PACKAGE_DIR>, and re-generated after the struct is changed.
+
 -h: print help
 */
 package main
@@ -48,6 +52,9 @@ type packageInfo struct {
 	name      string
 	files     []*pkgFileInfo
 	bigEndian bool
+	// import name and path of package if it's imported by the package of struct
+	qualifier  string
+	importPath string
 }
 
 type schemaDef struct {
@@ -55,6 +62,14 @@ type schemaDef struct {
 	Properties map[string]*schemaDef `json:"properties,omitempty"`
 	Items      *schemaDef            `json:"items,omitempty"`
 	Order      []string              `json:"order,omitempty"`
+
+	// Go type information for code generation
+	goField    string            // field name of property in struct
+	goType     string            // type expression of value, empty if it's anonymous type
+	goPtr      bool              // whether the value is a pointer
+	goArray    bool              // whether the value is a fixed-size array instead of slice
+	goImports  map[string]string // import names and paths which are referenced by goType
+	goExternal bool              // whether the struct is declared in imported package
 }
 
 type paramSet struct {
@@ -63,11 +78,14 @@ type paramSet struct {
 	outputFile string
 	bigEndian  bool
 	pretty     bool
+	genCode    bool
 }
 
 var params paramSet
 
-func init() {
+// parseParams parses command line options into params, it's called by main instead of init function,
+// so the tests of package aren't affected by the options.
+func parseParams() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: jsonpack-parser -s <PACKAGE_DIR> -n <STRUCT_NAME> [options]\n")
 		fmt.Fprintf(os.Stderr, "jsonpack-parser is a helper tool to generate schema definition text from struct in package.\n\n")
@@ -79,6 +97,7 @@ func init() {
 	flag.StringVar(&params.outputFile, "o", "", "output file name for schema definition, program will output to stdout if this option not specified")
 	flag.BoolVar(&params.bigEndian, "b", false, "generate number type with big-endian byte order, default is little-endian if this option not set")
 	flag.BoolVar(&params.pretty, "p", false, "prettify output JSON text of schema definition with indentation")
+	flag.BoolVar(&params.genCode, "g", false, "generate Go source of MarshalJSONPack/UnmarshalJSONPack methods of struct instead of schema definition")
 
 	flag.Parse()
 
@@ -134,6 +153,19 @@ func (p *packageInfo) buildBultinType(data string) (*schemaDef, bool) {
 }
 
 func (p *packageInfo) parseNode(fileInfo *pkgFileInfo, node ast.Node) (*schemaDef, error) {
+	sch, err := p.parseNodeType(fileInfo, node)
+	if err != nil || sch == nil {
+		return sch, err
+	}
+
+	// the type of outer node overrides inner node, e.g. the name of named type overrides its underlying type
+	sch.goImports = make(map[string]string)
+	sch.goType = p.exprString(fileInfo, node, sch.goImports)
+	_, sch.goPtr = node.(*ast.StarExpr)
+	return sch, nil
+}
+
+func (p *packageInfo) parseNodeType(fileInfo *pkgFileInfo, node ast.Node) (*schemaDef, error) {
 	switch node := node.(type) {
 	case *ast.Ident:
 		data, ok := p.buildBultinType(node.Name)
@@ -155,7 +187,7 @@ func (p *packageInfo) parseNode(fileInfo *pkgFileInfo, node ast.Node) (*schemaDe
 		if err != nil {
 			return nil, err
 		}
-		st := schemaDef{Type: "array", Items: arrSt}
+		st := schemaDef{Type: "array", Items: arrSt, goArray: node.Len != nil}
 		return &st, nil
 
 	case *ast.StarExpr:
@@ -172,7 +204,7 @@ func (p *packageInfo) parseNode(fileInfo *pkgFileInfo, node ast.Node) (*schemaDe
 			return nil, fmt.Errorf("package %s not found", modName)
 		}
 
-		extSch, err := parsePackageNode(extPkg.dir, modType)
+		extSch, err := parsePackageNode(extPkg, modName, modType)
 		if err != nil {
 			return nil, err
 		}
@@ -200,7 +232,7 @@ func parseFieldName(name string, tag *ast.BasicLit) string {
 }
 
 func (p *packageInfo) parseStruct(fileInfo *pkgFileInfo, stAst *ast.StructType) (*schemaDef, error) {
-	st := schemaDef{Type: "object"}
+	st := schemaDef{Type: "object", goExternal: p.qualifier != ""}
 	st.Properties = make(map[string]*schemaDef)
 	st.Order = make([]string, 0, len(stAst.Fields.List))
 
@@ -224,12 +256,18 @@ func (p *packageInfo) parseStruct(fileInfo *pkgFileInfo, stAst *ast.StructType)
 			switch typ := field.Type.(type) {
 			case *ast.Ident:
 				fieldName = parseFieldName(typ.Name, field.Tag)
+				if sch != nil {
+					sch.goField = typ.Name
+				}
 				err = mergeEmbedField(fieldName, &st, sch)
 				if err != nil {
 					return nil, err
 				}
 			case *ast.SelectorExpr:
 				fieldName = parseFieldName(typ.Sel.Name, field.Tag)
+				if sch != nil {
+					sch.goField = typ.Sel.Name
+				}
 				err = mergeEmbedField(fieldName, &st, sch)
 				if err != nil {
 					return nil, err
@@ -248,6 +286,7 @@ func (p *packageInfo) parseStruct(fileInfo *pkgFileInfo, stAst *ast.StructType)
 				continue
 			}
 
+			fieldProp.goField = field.Names[0].Name
 			st.Properties[fieldName] = fieldProp
 			st.Order = append(st.Order, fieldName)
 		}
@@ -350,14 +389,14 @@ func (p *packageInfo) getNode(name string) (*pkgFileInfo, ast.Node) {
 }
 
 func parsePackageStruct(pkgDir string, name string) (*schemaDef, error) {
-	return _parsePackage(pkgDir, name, true)
+	return _parsePackage(pkgDir, name, true, "", "")
 }
 
-func parsePackageNode(pkgDir string, name string) (*schemaDef, error) {
-	return _parsePackage(pkgDir, name, false)
+func parsePackageNode(extPkg *importInfo, qualifier string, name string) (*schemaDef, error) {
+	return _parsePackage(extPkg.dir, name, false, qualifier, extPkg.path)
 }
 
-func _parsePackage(pkgDir string, name string, findStruct bool) (*schemaDef, error) {
+func _parsePackage(pkgDir string, name string, findStruct bool, qualifier string, importPath string) (*schemaDef, error) {
 	fset := token.NewFileSet() // positions are relative to fset
 	// Parse src but stop after processing the imports.
 	pkgs, err := parser.ParseDir(fset, pkgDir, nil, 0)
@@ -370,6 +409,8 @@ func _parsePackage(pkgDir string, name string, findStruct bool) (*schemaDef, err
 	if err != nil {
 		return nil, err
 	}
+	pkgInfo.qualifier = qualifier
+	pkgInfo.importPath = importPath
 
 	fileInfo, node := pkgInfo.getNode(name)
 	if fileInfo == nil {
@@ -473,6 +514,8 @@ func newPackageInfo(pkgs map[string]*ast.Package, pkgDir string, bigEndian bool)
 }
 
 func main() {
+	parseParams()
+
 	var err error
 	if !isDirectory(params.srcDir) {
 		fmt.Fprintf(os.Stderr, "package dir '%s' is not exist\n", params.srcDir)
@@ -485,7 +528,20 @@ func main() {
 	}
 
 	var schText []byte
-	if params.pretty {
+	if params.genCode {
+		if err != nil {
+			os.Exit(1)
+		}
+		var pkgName string
+		pkgName, err = parsePackageName(params.srcDir)
+		if err == nil {
+			schText, err = generateGoCode(pkgName, params.structName, sch)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Generate Go source fail, err: %v\n", err)
+			os.Exit(1)
+		}
+	} else if params.pretty {
 		schText, err = json.MarshalIndent(sch, "", "  ")
 	} else {
 		schText, err = json.Marshal(sch)
@@ -507,7 +563,11 @@ func main() {
 			os.Exit(1)
 		}
 		_ = f.Close()
-		fmt.Printf("Schema definition has generated to output file %s\n", params.outputFile)
+		if params.genCode {
+			fmt.Printf("Go source has generated to output file %s\n", params.outputFile)
+		} else {
+			fmt.Printf("Schema definition has generated to output file %s\n", params.outputFile)
+		}
 	} else {
 		fmt.Fprintln(os.Stdout, string(schText))
 	}
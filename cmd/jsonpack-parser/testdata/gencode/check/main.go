@@ -0,0 +1,119 @@
+// check compares the generated methods of Record with the encoding and decoding of jsonpack schema.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/arloliu/jsonpack"
+
+	"example.com/gencode"
+	"example.com/gencode/geo"
+)
+
+// plainRecord has the same fields as gencode.Record without the generated methods
+type plainRecord gencode.Record
+
+func samples() []gencode.Record {
+	score := 97.5
+	label := "primary"
+	return []gencode.Record{
+		{
+			ID:       -42,
+			Name:     "alpha",
+			Level:    65535,
+			Active:   true,
+			Delta:    -3,
+			Ratio:    0.25,
+			Score:    &score,
+			Label:    &label,
+			Payload:  []byte{0, 1, 0xfe, 0xff},
+			Checksum: [4]uint8{0xde, 0xad, 0xbe, 0xef},
+			Tags:     []gencode.Tag{{Key: "k1", Value: "v1"}, {Key: "", Value: "v2"}},
+			Owner:    &gencode.Tag{Key: "owner", Value: "root"},
+			Location: geo.Point{Lat: 25.03, Lng: 121.56, Alt: -10},
+			Route:    []*geo.Point{{Lat: 1, Lng: 2, Alt: 3}, {Lat: -1.5}},
+		},
+		{
+			Score:   new(float64),
+			Label:   new(string),
+			Payload: []byte{},
+			Tags:    []gencode.Tag{},
+			Owner:   &gencode.Tag{},
+			Route:   []*geo.Point{},
+		},
+	}
+}
+
+func check() error {
+	sch, err := jsonpack.NewJSONPack().AddSchema("record", plainRecord{}, jsonpack.LittleEndian)
+	if err != nil {
+		return err
+	}
+	schDef, err := sch.GetSchemaDef()
+	if err != nil {
+		return err
+	}
+	if fingerprint := jsonpack.SchemaFingerprint(schDef); (gencode.Record{}).JSONPackFingerprint() != fingerprint {
+		return fmt.Errorf("generated fingerprint %s, expect: %s", (gencode.Record{}).JSONPackFingerprint(), fingerprint)
+	}
+
+	for i, record := range samples() {
+		record := record
+		expData, err := sch.Encode((*plainRecord)(&record))
+		if err != nil {
+			return fmt.Errorf("sample %d: encode with schema fail, err: %v", i, err)
+		}
+		data, err := record.MarshalJSONPack(nil)
+		if err != nil {
+			return fmt.Errorf("sample %d: MarshalJSONPack fail, err: %v", i, err)
+		}
+		if !bytes.Equal(data, expData) {
+			return fmt.Errorf("sample %d: MarshalJSONPack result: %v, expect: %v", i, data, expData)
+		}
+
+		var decoded gencode.Record
+		if err = decoded.UnmarshalJSONPack(data); err != nil {
+			return fmt.Errorf("sample %d: UnmarshalJSONPack fail, err: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded, record) {
+			return fmt.Errorf("sample %d: UnmarshalJSONPack result: %+v, expect: %+v", i, decoded, record)
+		}
+
+		var plainDecoded plainRecord
+		if err = sch.Decode(data, &plainDecoded); err != nil {
+			return fmt.Errorf("sample %d: decode with schema fail, err: %v", i, err)
+		}
+		if !reflect.DeepEqual(gencode.Record(plainDecoded), record) {
+			return fmt.Errorf("sample %d: decode with schema result: %+v, expect: %+v", i, plainDecoded, record)
+		}
+
+		// truncated data returns error instead of panic
+		if err = decoded.UnmarshalJSONPack(data[:len(data)/2]); err == nil {
+			return fmt.Errorf("sample %d: UnmarshalJSONPack with truncated data should fail", i)
+		}
+	}
+
+	// nil pointers fail with both the generated method and schema definition
+	record := samples()[0]
+	record.Route[1] = nil
+	if _, err = sch.Encode((*plainRecord)(&record)); err == nil {
+		return fmt.Errorf("encode nil pointer with schema should fail")
+	}
+	if _, err = record.MarshalJSONPack(nil); err == nil {
+		return fmt.Errorf("MarshalJSONPack with nil pointer should fail")
+	}
+	if _, err = sch.Encode(&record); err == nil {
+		return fmt.Errorf("encode nil pointer with generated method should fail")
+	}
+	return nil
+}
+
+func main() {
+	if err := check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,7 @@
+package geo
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+	Alt int32   `json:"alt"`
+}
@@ -0,0 +1,29 @@
+package gencode
+
+import "example.com/gencode/geo"
+
+type Level uint16
+
+type Name string
+
+type Tag struct {
+	Key   string `json:"key"`
+	Value Name   `json:"value"`
+}
+
+type Record struct {
+	ID       int64        `json:"id"`
+	Name     Name         `json:"name"`
+	Level    Level        `json:"level"`
+	Active   bool         `json:"active"`
+	Delta    int8         `json:"delta"`
+	Ratio    float32      `json:"ratio"`
+	Score    *float64     `json:"score"`
+	Label    *string      `json:"label"`
+	Payload  []byte       `json:"payload"`
+	Checksum [4]uint8     `json:"checksum"`
+	Tags     []Tag        `json:"tags"`
+	Owner    *Tag         `json:"owner"`
+	Location geo.Point    `json:"location"`
+	Route    []*geo.Point `json:"route"`
+}
@@ -0,0 +1,143 @@
+/*
+jsonpack-ts is a tool to generate TypeScript types and the equivalent schema registration of
+Buffer Plus from schema definition file, and the golden vectors which are used to test jsonpack
+and Buffer Plus against each other.
+
+Usage: jsonpack-ts -i <SCHEMA_FILE> -n <TYPE_NAME> [options]
+
+<SCHEMA_FILE> is the schema definition file in JSON format, or in YAML format if the file extension
+is ".yaml" or ".yml".
+
+<TYPE_NAME> is the name of top-level type and the registered schema, the nested interfaces are named
+by <TYPE_NAME> followed by the upper camel case of property names.
+
+Optional Options
+-o: output file name for TypeScript source, program will output to stdout if this option not specified
+
+-v: the JSON file of sample values array, the golden vectors of samples will be generated if this
+option specified
+
+-g: output file name for golden vectors, default is "<TYPE_NAME>_golden.json"
+
+-h: print help
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/arloliu/jsonpack"
+	"github.com/arloliu/jsonpack/internal/schemafile"
+)
+
+type paramSet struct {
+	schemaFile string
+	typeName   string
+	outputFile string
+	sampleFile string
+	goldenFile string
+}
+
+var params paramSet
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: jsonpack-ts -i <SCHEMA_FILE> -n <TYPE_NAME> [options]\n")
+		fmt.Fprintf(os.Stderr, "jsonpack-ts is a helper tool to generate TypeScript types and Buffer Plus schema from schema definition file.\n\n")
+		fmt.Fprintf(os.Stderr, "Available options:\n\n")
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&params.schemaFile, "i", "", "the schema definition file in JSON or YAML format")
+	flag.StringVar(&params.typeName, "n", "", "the name of top-level type and the registered schema")
+	flag.StringVar(&params.outputFile, "o", "", "output file name for TypeScript source, program will output to stdout if this option not specified")
+	flag.StringVar(&params.sampleFile, "v", "", "the JSON file of sample values array for generating golden vectors")
+	flag.StringVar(&params.goldenFile, "g", "", "output file name for golden vectors, default is \"<TYPE_NAME>_golden.json\"")
+
+	flag.Parse()
+
+	if params.schemaFile == "" || params.typeName == "" {
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\n-i and -n options are required.\n")
+		os.Exit(2)
+	}
+	if params.goldenFile == "" {
+		params.goldenFile = params.typeName + "_golden.json"
+	}
+}
+
+func loadSamples(fileName string) ([]interface{}, error) {
+	data, err := ioutil.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []interface{}{}
+	err = json.Unmarshal(data, &samples)
+	if err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func main() {
+	schDef, err := schemafile.Load(params.schemaFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load schema definition file %s fail, err: %v\n", params.schemaFile, err)
+		os.Exit(1)
+	}
+
+	// make sure the schema definition is valid before generating TypeScript source
+	sch, err := jsonpack.NewJSONPack().AddSchema(params.typeName, *schDef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid schema definition, err: %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := jsonpack.ToTypeScript(schDef, params.typeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Generate TypeScript source fail, err: %v\n", err)
+		os.Exit(1)
+	}
+
+	header := fmt.Sprintf("// Code generated by jsonpack-ts from %s. DO NOT EDIT.\n\n", filepath.Base(params.schemaFile))
+	src = append([]byte(header), src...)
+
+	if params.outputFile != "" {
+		err = ioutil.WriteFile(params.outputFile, src, 0644) //nolint:gosec
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Write TypeScript source to output file %s fail, err: %v\n", params.outputFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("TypeScript source has generated to output file %s\n", params.outputFile)
+	} else {
+		fmt.Fprint(os.Stdout, string(src))
+	}
+
+	if params.sampleFile == "" {
+		return
+	}
+
+	samples, err := loadSamples(params.sampleFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load sample file %s fail, err: %v\n", params.sampleFile, err)
+		os.Exit(1)
+	}
+
+	golden, err := sch.GoldenVectors(samples)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Generate golden vectors fail, err: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = ioutil.WriteFile(params.goldenFile, golden, 0644) //nolint:gosec
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Write golden vectors to output file %s fail, err: %v\n", params.goldenFile, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Golden vectors have generated to output file %s\n", params.goldenFile)
+}
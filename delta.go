@@ -0,0 +1,200 @@
+package jsonpack
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+// Delta represents the structural difference between two encoded documents of a schema,
+// it's created by Schema.Diff method and applied by Schema.ApplyDelta method.
+type Delta struct {
+	Changes []DeltaChange `json:"changes"`
+}
+
+// DeltaChange represents a changed property of document.
+type DeltaChange struct {
+	// property path of the changed value, the syntax is the same as Schema.Get method
+	Path string `json:"path"`
+	// encoded bytes of the new value
+	Value []byte `json:"value"`
+}
+
+// DeltaSchemaDef is the schema definition of encoded Delta, the encoded Delta can
+// be decoded by any jsonpack implementation with this schema definition.
+var DeltaSchemaDef = SchemaDef{
+	Type: "object",
+	Properties: map[string]*SchemaDef{
+		"changes": {
+			Type: "array",
+			Items: &SchemaDef{
+				Type: "object",
+				Properties: map[string]*SchemaDef{
+					"path":  {Type: "string"},
+					"value": {Type: "array", Items: &SchemaDef{Type: "uint8"}},
+				},
+				Order: []string{"path", "value"},
+			},
+		},
+	},
+	Order: []string{"changes"},
+}
+
+var deltaSchema *Schema
+
+func init() {
+	deltaSchema = newSchema("jsonpack.Delta", DeltaSchemaDef)
+	if err := deltaSchema.build(); err != nil {
+		panic(err)
+	}
+}
+
+// Encode encodes delta with DeltaSchemaDef schema definition.
+func (d *Delta) Encode() ([]byte, error) {
+	return deltaSchema.Encode(d)
+}
+
+// DecodeDelta decodes delta which encoded by Delta.Encode method.
+func DecodeDelta(data []byte) (*Delta, error) {
+	d := Delta{}
+	err := deltaSchema.Decode(data, &d)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+/*
+Diff compares two encoded documents a and b, and returns the delta that converts a to b.
+
+The delta contains the paths and encoded values of changed properties, the properties of
+object and the items of array are compared recursively, and the array which length
+changed will be replaced entirely.
+
+It returns *DecodeError error if a or b is invalid encoded data.
+
+Example of replicating state snapshots:
+	delta, err := sch.Diff(prevSnapshot, currSnapshot)
+	deltaData, err := delta.Encode()
+
+	// on the receiver side
+	delta, err := jsonpack.DecodeDelta(deltaData)
+	currSnapshot, err := sch.ApplyDelta(prevSnapshot, delta)
+*/
+func (s *Schema) Diff(a []byte, b []byte) (*Delta, error) {
+	d := Delta{Changes: make([]DeltaChange, 0)}
+	err := diffValue(ibuf.From(a), ibuf.From(b), s.rootOp, "", &d)
+	if err != nil {
+		return nil, errors.WithStack(&DecodeError{s.Name, err})
+	}
+	return &d, nil
+}
+
+/*
+ApplyDelta applies delta d to encoded document base, and returns the encoded document
+after applying, base will not be modified.
+
+It returns *PathError error if the path of change doesn't exist in base, or the
+value of change is invalid.
+*/
+func (s *Schema) ApplyDelta(base []byte, d *Delta) ([]byte, error) {
+	result := append(make([]byte, 0, len(base)), base...)
+	for _, change := range d.Changes {
+		buf := ibuf.From(result)
+		opNode, err := s.locate(buf, change.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		start := buf.Offset()
+		err = skipValue(buf, opNode)
+		if err != nil {
+			return nil, errors.WithStack(&PathError{s.Name, change.Path, err})
+		}
+		end := buf.Offset()
+
+		// the value should be exactly one encoded value of property
+		valueBuf := ibuf.From(change.Value)
+		err = skipValue(valueBuf, opNode)
+		if err == nil && remainBytes(valueBuf) != 0 {
+			err = errors.Errorf("%d trailing bytes in value of change", remainBytes(valueBuf))
+		}
+		if err != nil {
+			return nil, errors.WithStack(&PathError{s.Name, change.Path, err})
+		}
+
+		result = splice(result, start, end, change.Value)
+	}
+	return result, nil
+}
+
+// diffValue compares values of opNode in bufA and bufB, and appends changes to d.
+func diffValue(bufA *ibuf.Buffer, bufB *ibuf.Buffer, opNode *operation, path string, d *Delta) error {
+	var err error
+
+	switch {
+	case opNode.handlerType == objectOpType:
+		for _, childNode := range opNode.children {
+			err = diffValue(bufA, bufB, childNode, formatPath(path, pathElem{name: childNode.propName}), d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case isArrayOp(opNode):
+		var lenA, lenB uint64
+		startA, startB := bufA.Offset(), bufB.Offset()
+		lenA, err = readLength(bufA)
+		if err != nil {
+			return err
+		}
+		lenB, err = readLength(bufB)
+		if err != nil {
+			return err
+		}
+
+		if lenA == lenB {
+			itemOp := opNode.children[0]
+			if itemOp.fixedSize != 0 && lenA > uint64(remainBytes(bufA)) {
+				return errors.WithStack(ibuf.BufferOverreadError)
+			}
+			for i := uint64(0); i < lenA; i++ {
+				err = diffValue(bufA, bufB, itemOp, formatPath(path, pathElem{index: i, isIndex: true}), d)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		// the length of array changed, replace whole array
+		bufA.SeekUnsafe(startA, false)
+		bufB.SeekUnsafe(startB, false)
+	}
+
+	rawA, err := readRawValue(bufA, opNode)
+	if err != nil {
+		return err
+	}
+	rawB, err := readRawValue(bufB, opNode)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(rawA, rawB) {
+		d.Changes = append(d.Changes, DeltaChange{Path: path, Value: append([]byte{}, rawB...)})
+	}
+	return nil
+}
+
+// readRawValue skips the value of opNode, and returns the encoded bytes of value.
+func readRawValue(buf *ibuf.Buffer, opNode *operation) ([]byte, error) {
+	start := buf.Offset()
+	err := skipValue(buf, opNode)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes()[start:buf.Offset()], nil
+}
@@ -0,0 +1,98 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestDiff(t *testing.T) {
+	var err error
+
+	sch := jsonPack.GetSchema("complex")
+	base := testdata.ComplexExpData
+
+	target := append([]byte{}, base...)
+	if err = sch.Patch(target, "category", 2); err != nil {
+		t.Fatalf("Patch fail, err: %+v", err)
+	}
+	if target, err = sch.PatchResize(target, "accounts[1].currentStatus.msg", "new message"); err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+	if target, err = sch.PatchResize(target, "positions", []interface{}{uint8(1), uint8(2)}); err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+
+	delta, err := sch.Diff(base, target)
+	if err != nil {
+		t.Fatalf("Diff fail, err: %+v", err)
+	}
+	expPaths := []string{"category", "positions", "accounts[1].currentStatus.msg"}
+	if len(delta.Changes) != len(expPaths) {
+		t.Fatalf("Diff, expect changes: %v, got: %+v", expPaths, delta.Changes)
+	}
+	for i, path := range expPaths {
+		if delta.Changes[i].Path != path {
+			t.Errorf("Diff, expect path: %s, got: %s", path, delta.Changes[i].Path)
+		}
+	}
+
+	// encode and decode delta
+	deltaData, err := delta.Encode()
+	if err != nil {
+		t.Fatalf("Encode delta fail, err: %+v", err)
+	}
+	if err = deltaSchema.Validate(deltaData); err != nil {
+		t.Errorf("Validate delta fail, err: %+v", err)
+	}
+	delta, err = DecodeDelta(deltaData)
+	if err != nil {
+		t.Fatalf("DecodeDelta fail, err: %+v", err)
+	}
+
+	result, err := sch.ApplyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("ApplyDelta fail, err: %+v", err)
+	}
+	compareBytes(t, target, result)
+	compareBytes(t, testdata.ComplexExpData, base)
+
+	// no changes
+	delta, err = sch.Diff(base, base)
+	if err != nil || len(delta.Changes) != 0 {
+		t.Errorf("Diff same data should be empty, got: %+v, err: %+v", delta, err)
+	}
+
+	// array schema with different length
+	sliceSch := jsonPack.GetSchema("sliceObject")
+	sliceTarget, err := sliceSch.PatchResize(append([]byte{}, testdata.SliceExpData...), "", []interface{}{})
+	if err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+	delta, err = sliceSch.Diff(testdata.SliceExpData, sliceTarget)
+	if err != nil || len(delta.Changes) != 1 || delta.Changes[0].Path != "" {
+		t.Fatalf("Diff array schema fail, got: %+v, err: %+v", delta, err)
+	}
+	result, err = sliceSch.ApplyDelta(testdata.SliceExpData, delta)
+	if err != nil {
+		t.Fatalf("ApplyDelta fail, err: %+v", err)
+	}
+	compareBytes(t, sliceTarget, result)
+
+	var decodeErr *DecodeError
+	if _, err = sch.Diff(base, target[:10]); !errors.As(err, &decodeErr) {
+		t.Errorf("Diff truncated data should fail, err: %v", err)
+	}
+
+	var pathErr *PathError
+	invalid := &Delta{Changes: []DeltaChange{{Path: "category", Value: []byte{1, 2}}}}
+	if _, err = sch.ApplyDelta(base, invalid); !errors.As(err, &pathErr) {
+		t.Errorf("ApplyDelta invalid value should fail, err: %v", err)
+	}
+	invalid = &Delta{Changes: []DeltaChange{{Path: "unknown", Value: []byte{1}}}}
+	if _, err = sch.ApplyDelta(base, invalid); !errors.As(err, &pathErr) {
+		t.Errorf("ApplyDelta invalid path should fail, err: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package jsonpack
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+const (
+	// maximum number of bytes in hex view of each value
+	dumpHexLimit = 16
+	// maximum number of characters of string value
+	dumpStringLimit = 40
+)
+
+/*
+Dump writes human-readable annotation of encoded data to w, it's useful for
+inspecting invalid or unexpected encoded data.
+
+Each line describes a value with its byte offset, byte length, property path,
+schema type and Go type, value and hex view of encoded bytes. The lines of object and
+array cover the encoded bytes of their properties and items, and the hex view of array
+shows the length prefix.
+
+The annotation of values before the invalid part will be written when data is invalid,
+and it returns *DecodeError error.
+
+Example output:
+	OFFSET  LENGTH  PATH        TYPE               VALUE          HEX
+	0x0000  11      (root)      object             {2 properties}
+	0x0000  6       name        string/string      "hello"        05 68 65 6c 6c 6f
+	0x0006  4       area        uint32le/uint32    1              01 00 00 00
+	0x000a  1       tags        array              [0 items]      00
+*/
+func (s *Schema) Dump(data []byte, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OFFSET\tLENGTH\tPATH\tTYPE\tVALUE\tHEX")
+
+	buf := ibuf.From(data)
+	err := dumpValue(tw, buf, s.rootOp, "")
+	if err == nil && remainBytes(buf) > 0 {
+		offset := buf.Offset()
+		fmt.Fprintf(tw, "0x%04x\t%d\t\ttrailing bytes\t\t%s\n", offset, len(data)-int(offset), hexView(data[offset:]))
+	}
+	if err != nil {
+		fmt.Fprintf(tw, "0x%04x\t\t\terror\t%v\t\n", buf.Offset(), err)
+	}
+
+	flushErr := tw.Flush()
+	if err != nil {
+		return errors.WithStack(&DecodeError{s.Name, err})
+	}
+	return flushErr
+}
+
+// dumpValue writes annotation of value described by opNode and its properties or items to w.
+func dumpValue(w io.Writer, buf *ibuf.Buffer, opNode *operation, path string) error {
+	var err error
+
+	start := buf.Offset()
+	displayPath := path
+	if displayPath == "" {
+		displayPath = "(root)"
+	}
+
+	// byte length of value, or "?" if the value is invalid
+	length := "?"
+	if skipValue(buf, opNode) == nil {
+		length = fmt.Sprintf("%d", buf.Offset()-start)
+	}
+	buf.SeekUnsafe(start, false)
+
+	switch {
+	case opNode.handlerType == objectOpType:
+		fmt.Fprintf(w, "0x%04x\t%s\t%s\tobject\t{%d properties}\t\n", start, length, displayPath, len(opNode.children))
+		for _, childNode := range opNode.children {
+			err = dumpValue(w, buf, childNode, formatPath(path, pathElem{name: childNode.propName}))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case isArrayOp(opNode):
+		var itemsLen uint64
+		itemsLen, err = readLength(buf)
+		if err != nil {
+			buf.SeekUnsafe(start, false)
+			return errors.Wrapf(err, "'%s'", displayPath)
+		}
+		prefix := buf.Bytes()[start:buf.Offset()]
+		fmt.Fprintf(w, "0x%04x\t%s\t%s\tarray\t[%d items]\t%s\n", start, length, displayPath, itemsLen, hexView(prefix))
+
+		itemOp := opNode.children[0]
+		if itemOp.fixedSize == 0 {
+			// the empty object items take no bytes, summarizes them instead of writing a line for each item
+			if itemsLen > 0 {
+				fmt.Fprintf(w, "0x%04x\t0\t%s[]\tobject\t{%d empty items}\t\n", buf.Offset(), path, itemsLen)
+			}
+			return nil
+		}
+		if itemsLen > uint64(remainBytes(buf)) {
+			buf.SeekUnsafe(start, false)
+			return errors.Wrapf(ibuf.BufferOverreadError, "'%s'", displayPath)
+		}
+		for i := uint64(0); i < itemsLen; i++ {
+			err = dumpValue(w, buf, itemOp, formatPath(path, pathElem{index: i, isIndex: true}))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		var raw []byte
+		var val interface{}
+		raw, err = readRawValue(buf, opNode)
+		if err != nil {
+			// reports the offset and path of invalid value
+			buf.SeekUnsafe(start, false)
+			return errors.Wrapf(err, "'%s'", displayPath)
+		}
+		val, err = decodeValue(ibuf.From(raw), opNode)
+		if err != nil {
+			return err
+		}
+
+		var valStr string
+		if str, ok := val.(string); ok {
+			if len(str) > dumpStringLimit {
+				valStr = fmt.Sprintf("%q...", str[:dumpStringLimit])
+			} else {
+				valStr = fmt.Sprintf("%q", str)
+			}
+		} else {
+			valStr = fmt.Sprintf("%v", val)
+		}
+		fmt.Fprintf(w, "0x%04x\t%d\t%s\t%s/%T\t%s\t%s\n", start, len(raw), displayPath, opTypeName(opNode.handlerType), val, valStr, hexView(raw))
+		return nil
+	}
+}
+
+// hexView returns hex representation of data, the bytes exceed dumpHexLimit are omitted.
+func hexView(data []byte) string {
+	var sb strings.Builder
+	for i, b := range data {
+		if i >= dumpHexLimit {
+			sb.WriteString(" ...")
+			break
+		}
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%02x", b)
+	}
+	return sb.String()
+}
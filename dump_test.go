@@ -0,0 +1,68 @@
+package jsonpack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestDump(t *testing.T) {
+	var err error
+	var out bytes.Buffer
+
+	sch := jsonPack.GetSchema("sliceObject")
+	err = sch.Dump(testdata.SliceExpData, &out)
+	if err != nil {
+		t.Fatalf("Dump fail, err: %+v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// header, root array, and 5 lines for each item
+	if len(lines) != 17 {
+		t.Fatalf("Dump, expect 17 lines, got: %d\n%s", len(lines), out.String())
+	}
+	expLines := map[int][]string{
+		1: {"0x0000", "55", "(root)", "array", "[3 items]", "03"},
+		3: {"0x0001", "4", "[0].num1", "int32le/int32", "10001", "11 27 00 00"},
+		6: {"0x000d", "6", "[0].obj.name", "string/string", `"test1"`, "05 74 65 73 74 31"},
+	}
+	for i, fields := range expLines {
+		for _, field := range fields {
+			if !strings.Contains(lines[i], field) {
+				t.Errorf("Dump line %d, expect contains: %s, got: %s", i, field, lines[i])
+			}
+		}
+	}
+
+	// invalid data
+	out.Reset()
+	err = jsonPack.GetSchema("complex").Dump(testdata.ComplexExpData[:60], &out)
+	var expectErr *DecodeError
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Dump truncated data should fail, err: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	lastLine := lines[len(lines)-1]
+	if !strings.HasPrefix(lastLine, "0x0035") || !strings.Contains(lastLine, "error") || !strings.Contains(lastLine, "'ips[4]'") {
+		t.Errorf("Dump truncated data, got:\n%s", out.String())
+	}
+
+	// a huge number of empty object items is summarized in one line
+	sch, err = NewJSONPack().AddSchema("emptyItems", `{"type": "array", "items": {"type": "object", "properties": {}, "order": []}}`)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	out.Reset()
+	err = sch.Dump([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}, &out)
+	if err != nil {
+		t.Fatalf("Dump empty object items fail, err: %+v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], "{4294967295 empty items}") {
+		t.Errorf("Dump empty object items, got:\n%s", out.String())
+	}
+}
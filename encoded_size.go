@@ -0,0 +1,234 @@
+package jsonpack
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/modern-go/reflect2"
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+EncodedSize returns the exact byte length of encoded data of v without encoding it,
+it's useful for allocating buffer with exact size or sizing network frames up front.
+
+The valid types of v are the same as Encode method. The values of builtin fixed-width types
+are not checked, the size is only accurate when v can be encoded by Encode method.
+
+Example:
+	size, err := sch.EncodedSize(&info)
+	buf := make([]byte, 0, size)
+	err = sch.EncodeTo(&info, &buf)
+*/
+func (s *Schema) EncodedSize(v interface{}) (size int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case string:
+				err = errors.WithStack(&EncodeError{s.Name, errors.New(r)})
+			case error:
+				err = errors.WithStack(&EncodeError{s.Name, r})
+			}
+		}
+	}()
+
+	n, err := s.encodedSize(v)
+	if err != nil {
+		return 0, errors.WithStack(&EncodeError{s.Name, err})
+	}
+	return int(n), nil
+}
+
+func (s *Schema) encodedSize(d interface{}) (int64, error) {
+	switch d := d.(type) {
+	// fast path: use type assertion, it's faster then reflection
+	case map[string]interface{}:
+		return sizeDynamic(s.rootOp, d)
+	case *map[string]interface{}:
+		return sizeDynamic(s.rootOp, *d)
+	case []map[string]interface{}:
+		return sizeDynamic(s.rootOp, d)
+	case *[]map[string]interface{}:
+		return sizeDynamic(s.rootOp, *d)
+	case []interface{}:
+		return sizeDynamic(s.rootOp, d)
+	case *[]interface{}:
+		return sizeDynamic(s.rootOp, *d)
+	case *interface{}:
+		return s.encodedSize(*d)
+	}
+
+	// slow path: use reflection to check type
+	dType := reflect2.TypeOf(d)
+	switch dType.Kind() {
+	case reflect.Struct:
+		sop, err := s.getStructOperation(dType, d, false)
+		if err != nil {
+			return 0, err
+		}
+		return sizeStruct(sop, reflect2.PtrOf(d))
+
+	case reflect.Slice, reflect.Array:
+		var elemType reflect2.Type
+		if dType.Kind() == reflect.Slice {
+			elemType = dType.(*reflect2.UnsafeSliceType).Elem()
+		} else {
+			elemType = dType.(*reflect2.UnsafeArrayType).Elem()
+		}
+		switch elemType.Kind() {
+		case reflect.Struct:
+			sop, err := s.getStructOperation(elemType, d, false)
+			if err != nil {
+				return 0, err
+			}
+			return sizeStruct(sop, reflect2.PtrOf(d))
+		case reflect.Map, reflect.Interface:
+			return sizeDynamic(s.rootOp, d)
+		}
+		return 0, errors.WithStack(&WrongTypeError{elemType.String()})
+
+	case reflect.Ptr:
+		return s.encodedSize(toPtrElemType(dType).Indirect(d))
+	}
+	return 0, errors.WithStack(&WrongTypeError{dType.String()})
+}
+
+// sizeStruct returns the byte length of encoded value of struct operation, ptr points to the value.
+func sizeStruct(opNode *structOperation, ptr unsafe.Pointer) (int64, error) {
+	switch opNode.handlerType {
+	case structOpType:
+		var size int64
+		for _, childNode := range opNode.children {
+			fieldPtr := childNode.field.UnsafeGet(ptr)
+			// dereference pointer
+			if childNode.isPtrType {
+				fieldPtr = derefPtr(fieldPtr)
+			}
+			n, err := sizeStruct(childNode, fieldPtr)
+			if err != nil {
+				return 0, err
+			}
+			size += n
+		}
+		return size, nil
+
+	case objectOpType:
+		// map type in struct
+		return sizeDynamic(opNode.opInstance, opNode.opType.UnsafeIndirect(ptr))
+
+	case sliceOpType, arrayOpType:
+		var length int
+		itemOp := opNode.children[0]
+		if opNode.handlerType == sliceOpType {
+			length = opNode.opType.(*reflect2.UnsafeSliceType).UnsafeLengthOf(ptr)
+		} else {
+			length = opNode.opType.(*reflect2.UnsafeArrayType).Len()
+		}
+
+		size := ibuf.ByteLenVarUint(uint64(length))
+		// fast path: items have fixed size
+		if itemOp.opInstance.fixedSize >= 0 {
+			return size + int64(length)*itemOp.opInstance.fixedSize, nil
+		}
+		for i := 0; i < length; i++ {
+			var itemPtr unsafe.Pointer
+			if opNode.handlerType == sliceOpType {
+				itemPtr = opNode.opType.(*reflect2.UnsafeSliceType).UnsafeGetIndex(ptr, i)
+			} else {
+				itemPtr = opNode.opType.(*reflect2.UnsafeArrayType).UnsafeGetIndex(ptr, i)
+			}
+			// dereference pointer
+			if itemOp.isPtrType {
+				itemPtr = derefPtr(itemPtr)
+			}
+			n, err := sizeStruct(itemOp, itemPtr)
+			if err != nil {
+				return 0, err
+			}
+			size += n
+		}
+		return size, nil
+
+	case stringOpType:
+		length := uint64(len(*((*string)(ptr))))
+		return ibuf.ByteLenVarUint(length) + int64(length), nil
+
+	default:
+		return opNode.opInstance.fixedSize, nil
+	}
+}
+
+// sizeDynamic returns the byte length of encoded value of data with operation opNode.
+func sizeDynamic(opNode *operation, data interface{}) (int64, error) {
+	switch opNode.handlerType {
+	case objectOpType:
+		var size int64
+		for _, childNode := range opNode.children {
+			n, err := sizeDynamic(childNode, getPropData(childNode, data))
+			if err != nil {
+				return 0, err
+			}
+			size += n
+		}
+		return size, nil
+
+	case sliceOpType, arrayOpType:
+		itemOp := opNode.children[0]
+		switch items := data.(type) {
+		case []interface{}:
+			size := ibuf.ByteLenVarUint(uint64(len(items)))
+			for _, item := range items {
+				n, err := sizeDynamic(itemOp, item)
+				if err != nil {
+					return 0, err
+				}
+				size += n
+			}
+			return size, nil
+
+		case []map[string]interface{}:
+			size := ibuf.ByteLenVarUint(uint64(len(items)))
+			for _, item := range items {
+				n, err := sizeDynamic(itemOp, item)
+				if err != nil {
+					return 0, err
+				}
+				size += n
+			}
+			return size, nil
+
+		default:
+			fVal := reflect.ValueOf(data)
+			if !fVal.IsValid() || (fVal.Kind() != reflect.Slice && fVal.Kind() != reflect.Array) {
+				return 0, errors.WithStack(&WrongTypeError{fmt.Sprintf("%T", data)})
+			}
+			length := fVal.Len()
+			size := ibuf.ByteLenVarUint(uint64(length))
+			// fast path: items have fixed size
+			if itemOp.fixedSize >= 0 {
+				return size + int64(length)*itemOp.fixedSize, nil
+			}
+			for i := 0; i < length; i++ {
+				n, err := sizeDynamic(itemOp, fVal.Index(i).Interface())
+				if err != nil {
+					return 0, err
+				}
+				size += n
+			}
+			return size, nil
+		}
+
+	case stringOpType:
+		d, ok := data.(string)
+		if !ok {
+			return 0, errors.WithStack(&TypeAssertionError{data, "string"})
+		}
+		return ibuf.ByteLenVarUint(uint64(len(d))) + int64(len(d)), nil
+
+	default:
+		return opNode.fixedSize, nil
+	}
+}
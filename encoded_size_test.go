@@ -0,0 +1,52 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestEncodedSize(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"types", testdata.TypesMapData},
+		{"types", &testdata.TypesStructData},
+		{"complex", testdata.ComplexData},
+		{"complex", testdata.ComplexStructData},
+		{"complex", &testdata.ComplexStructData},
+		{"sliceObject", testdata.SliceData},
+		{"sliceObject", testdata.SliceMapData},
+		{"sliceObject", testdata.SliceStructData},
+		{"sliceObject", testdata.ArrayData},
+		{"sliceObject", testdata.ArrayMapData},
+		{"testStruct", &testdata.StructData},
+	}
+	for _, test := range tests {
+		sch := jsonPack.GetSchema(test.name)
+		encData, err := sch.Encode(test.data)
+		if err != nil {
+			t.Fatalf("Encode %s with %T fail, err: %+v", test.name, test.data, err)
+		}
+		size, err := sch.EncodedSize(test.data)
+		if err != nil {
+			t.Fatalf("EncodedSize %s with %T fail, err: %+v", test.name, test.data, err)
+		}
+		if size != len(encData) {
+			t.Errorf("EncodedSize %s with %T, expect: %d, got: %d", test.name, test.data, len(encData), size)
+		}
+	}
+
+	var expectErr *EncodeError
+	_, err := jsonPack.GetSchema("sliceObject").EncodedSize(123)
+	if !errors.As(err, &expectErr) {
+		t.Errorf("EncodedSize wrong type should fail, err: %v", err)
+	}
+	_, err = jsonPack.GetSchema("complex").EncodedSize(map[string]interface{}{"ips": []interface{}{1}})
+	if !errors.As(err, &expectErr) {
+		t.Errorf("EncodedSize wrong item type should fail, err: %v", err)
+	}
+}
@@ -69,6 +69,119 @@ func (e *SchemaNonExistError) Error() string {
 	return fmt.Sprintf("schema definition '%s' does not exist", e.Name)
 }
 
+// SchemaVersionNonExistError indicates an error that occurs when the version of pre-compiled schema definition does not exist.
+type SchemaVersionNonExistError struct {
+	Name    string // schema name
+	Version uint32 // schema version
+}
+
+func (e *SchemaVersionNonExistError) Error() string {
+	return fmt.Sprintf("version %d of schema definition '%s' does not exist", e.Version, e.Name)
+}
+
+// EnvelopeNonExistError indicates an error that occurs when envelope id or schema hasn't registered
+// for envelope encoding.
+type EnvelopeNonExistError struct {
+	Name string // schema name, empty if lookup by envelope id
+	ID   uint64 // envelope id
+}
+
+func (e *EnvelopeNonExistError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("schema definition '%s' is not registered for envelope", e.Name)
+	}
+	return fmt.Sprintf("envelope id %d is not registered", e.ID)
+}
+
+// PathError indicates an error that occurs when property path is invalid, or the property of path
+// doesn't exist in schema definition or encoded data.
+type PathError struct {
+	Name string // schema name
+	Path string // property path
+	Err  error  // actual error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("path '%s' of schema definition '%s' got error: %v", e.Path, e.Name, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// InferError represents an error from calling InferSchema function.
+type InferError struct {
+	Sample int    // index of sample, or -1 if the error isn't caused by a specific sample
+	Path   string // property path
+	Err    error  // actual error
+}
+
+func (e *InferError) Error() string {
+	msg := "infer schema"
+	if e.Sample >= 0 {
+		msg += fmt.Sprintf(" from sample %d", e.Sample)
+	}
+	if e.Path != "" {
+		msg += fmt.Sprintf(" at '%s'", e.Path)
+	}
+	return fmt.Sprintf("%s got error: %v", msg, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *InferError) Unwrap() error {
+	return e.Err
+}
+
+// ConvertError represents an error from converting schema of other formats to schema definition,
+// or from converting schema definition to other formats.
+type ConvertError struct {
+	Path string // location of the schema element that can't be converted
+	Err  error  // actual error
+}
+
+func (e *ConvertError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("convert schema got error: %v", e.Err.Error())
+	}
+	return fmt.Sprintf("convert schema at '%s' got error: %v", e.Path, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *ConvertError) Unwrap() error {
+	return e.Err
+}
+
+// IDLSyntaxError represents a syntax error of schema IDL from calling ParseIDL function.
+type IDLSyntaxError struct {
+	Line   int    // line number, starts from 1
+	Column int    // column number in bytes, starts from 1
+	Msg    string // description of error
+}
+
+func (e *IDLSyntaxError) Error() string {
+	return fmt.Sprintf("schema IDL syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// GoldenVectorError represents an error from generating or verifying golden vectors.
+type GoldenVectorError struct {
+	Name  string // schema name
+	Index int    // index of vector, or -1 if the error isn't caused by a specific vector
+	Err   error  // actual error
+}
+
+func (e *GoldenVectorError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("golden vectors of schema definition '%s' got error: %v", e.Name, e.Err.Error())
+	}
+	return fmt.Sprintf("golden vector %d of schema definition '%s' got error: %v", e.Index, e.Name, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *GoldenVectorError) Unwrap() error {
+	return e.Err
+}
+
 // CompileError represents an error from calling AddSchema method, it indicates there has an error occurs
 // in compiling procedure of schema definition.
 type CompileError struct {
@@ -114,3 +227,35 @@ func (e *DecodeError) Error() string {
 func (e *DecodeError) Unwrap() error {
 	return e.Err
 }
+
+// ValidationError represents an error from calling Validate method, it indicates the encoded data is invalid.
+type ValidationError struct {
+	Name   string // schema name
+	Offset int64  // the offset of encoded data where the error occurs
+	Err    error  // actual error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validate with schema definition '%s' got error at offset %d: %v", e.Name, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// TranscodeError represents an error from calling Transcode function.
+type TranscodeError struct {
+	From string // name of source schema
+	To   string // name of target schema
+	Err  error  // actual error
+}
+
+func (e *TranscodeError) Error() string {
+	return fmt.Sprintf("transcode from schema definition '%s' to '%s' got error: %v", e.From, e.To, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *TranscodeError) Unwrap() error {
+	return e.Err
+}
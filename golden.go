@@ -0,0 +1,201 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// GoldenVectorSet represents the document of golden vectors, which is used to test the encoders
+// and decoders of different languages against each other.
+type GoldenVectorSet struct {
+	Name       string         `json:"name"`       // schema name
+	Definition *SchemaDef     `json:"definition"` // schema definition
+	Vectors    []GoldenVector `json:"vectors"`
+}
+
+// GoldenVector represents a value and its encoded data.
+type GoldenVector struct {
+	Value   json.RawMessage `json:"value"`   // JSON document of value
+	Encoded string          `json:"encoded"` // hex string of encoded data
+}
+
+/*
+GoldenVectors encodes samples with the schema, and returns JSON document of GoldenVectorSet which
+contains schema definition, samples and encoded data of samples.
+
+The samples can be any data which can be encoded by Encode method, the values in document are decoded
+from encoded data, so they have property names of schema definition and the values are converted to
+the types of schema definition. The schema definition must be object or array type.
+
+The document can be verified by VerifyGoldenVectors function, and by the tests of other implementations,
+likes Buffer Plus for JavaScript, which encode the values and compare with the encoded data, and decode
+the encoded data and compare with the values. The 64-bit integers beyond 2^53 can't be represented
+exactly by JavaScript, avoid using them in samples.
+
+Example:
+	data, err := sch.GoldenVectors([]interface{}{&info1, &info2})
+	err = ioutil.WriteFile("info_golden.json", data, 0644)
+*/
+func (s *Schema) GoldenVectors(samples []interface{}) ([]byte, error) {
+	schDef, err := s.GetSchemaDef()
+	if err != nil {
+		return nil, errors.WithStack(&GoldenVectorError{s.Name, -1, err})
+	}
+
+	// the canonical schema definition can be used by Buffer Plus directly
+	set := GoldenVectorSet{Name: s.Name, Definition: canonicalSchemaDef(schDef), Vectors: make([]GoldenVector, 0, len(samples))}
+	for i, sample := range samples {
+		encoded, err := s.Encode(sample)
+		if err != nil {
+			return nil, errors.WithStack(&GoldenVectorError{s.Name, i, err})
+		}
+		decoded, err := s.decodeGoldenValue(encoded)
+		if err != nil {
+			return nil, errors.WithStack(&GoldenVectorError{s.Name, i, err})
+		}
+		value, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, errors.WithStack(&GoldenVectorError{s.Name, i, err})
+		}
+		set.Vectors = append(set.Vectors, GoldenVector{Value: value, Encoded: hex.EncodeToString(encoded)})
+	}
+
+	data, err := json.MarshalIndent(&set, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(&GoldenVectorError{s.Name, -1, err})
+	}
+	return data, nil
+}
+
+/*
+VerifyGoldenVectors verifies the document of golden vectors data, which might be generated by other
+implementations, it encodes every value and compares with the encoded data, and decodes the encoded
+data and compares with the value. The numbers of values are converted to the number types of schema
+definition exactly, so the 64-bit integers beyond 2^53 can be verified.
+
+It returns *GoldenVectorError error with the index of vector if the vector doesn't match.
+*/
+func VerifyGoldenVectors(data []byte) error {
+	set := GoldenVectorSet{}
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return errors.WithStack(&GoldenVectorError{"", -1, err})
+	}
+	if set.Definition == nil {
+		return errors.WithStack(&GoldenVectorError{set.Name, -1, errors.New("schema definition is missing")})
+	}
+
+	sch, err := NewJSONPack().AddSchema(set.Name, *set.Definition)
+	if err != nil {
+		return errors.WithStack(&GoldenVectorError{set.Name, -1, err})
+	}
+
+	for i, vec := range set.Vectors {
+		err = sch.verifyGoldenVector(vec)
+		if err != nil {
+			return errors.WithStack(&GoldenVectorError{set.Name, i, err})
+		}
+	}
+	return nil
+}
+
+func (s *Schema) verifyGoldenVector(vec GoldenVector) error {
+	expData, err := hex.DecodeString(vec.Encoded)
+	if err != nil {
+		return err
+	}
+	// keeps the numbers as literals, so the 64-bit integers aren't converted to floating numbers
+	var rawValue interface{}
+	dec := json.NewDecoder(bytes.NewReader(vec.Value))
+	dec.UseNumber()
+	err = dec.Decode(&rawValue)
+	if err != nil {
+		return err
+	}
+	expValue, err := goldenValue(s.rootOp, rawValue)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := s.Encode(expValue)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(encoded, expData) {
+		return errors.Errorf("encoded data of value is %s, expect: %s", hex.EncodeToString(encoded), vec.Encoded)
+	}
+
+	// the numbers of decoded value have the same Go types as the converted value
+	decoded, err := s.decodeGoldenValue(expData)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(decoded, expValue) {
+		value, _ := json.Marshal(decoded)
+		return errors.Errorf("decoded value is %s, expect: %s", value, vec.Value)
+	}
+	return nil
+}
+
+// goldenValue converts the numbers of v, which are json.Number, to the Go types of number types
+// in schema definition opNode, the same as the values decoded into map.
+func goldenValue(opNode *operation, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range opNode.children {
+			val, ok := v[child.propName]
+			if !ok {
+				continue
+			}
+			val, err := goldenValue(child, val)
+			if err != nil {
+				return nil, err
+			}
+			v[child.propName] = val
+		}
+	case []interface{}:
+		if !isArrayOp(opNode) {
+			return v, nil
+		}
+		for i := range v {
+			val, err := goldenValue(opNode.children[0], v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = val
+		}
+	case json.Number:
+		if !isNumberType(opNode.handlerType) {
+			return nil, errors.WithStack(&TypeAssertionError{v, opTypeName(opNode.handlerType)})
+		}
+		n, ok := parseNumber(v.String())
+		if !ok {
+			return nil, errors.Errorf("invalid number %s", v)
+		}
+		val, ok := n.goValue(opNode.handlerType)
+		if !ok {
+			return nil, errors.Errorf("value %s overflows %s type", v, opTypeName(opNode.handlerType))
+		}
+		return val, nil
+	}
+	return v, nil
+}
+
+// decodeGoldenValue decodes data into map or slice by the type of schema definition.
+func (s *Schema) decodeGoldenValue(data []byte) (interface{}, error) {
+	switch s.rootOp.handlerType {
+	case objectOpType:
+		m := make(map[string]interface{})
+		err := s.Decode(data, &m)
+		return m, err
+	case sliceOpType, arrayOpType:
+		a := make([]interface{}, 0)
+		err := s.Decode(data, &a)
+		return a, err
+	}
+	return nil, errors.Errorf("schema definition of '%s' type is not supported", opTypeName(s.rootOp.handlerType))
+}
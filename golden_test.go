@@ -0,0 +1,165 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+type goldenInfo struct {
+	Name string   `json:"name"`
+	Area uint32   `json:"area"`
+	Temp float64  `json:"temp"`
+	Tags []string `json:"tags"`
+}
+
+func TestGoldenVectors(t *testing.T) {
+	jsonPack := NewJSONPack()
+	sch, err := jsonPack.AddSchema("goldenInfo", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+			"area": map[string]interface{}{"type": "uint32le"},
+			"temp": map[string]interface{}{"type": "doublele"},
+			"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"order": []string{"name", "area", "temp", "tags"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	data, err := sch.GoldenVectors([]interface{}{
+		&goldenInfo{Name: "alpha", Area: 1, Temp: -2.5, Tags: []string{"a", "b"}},
+		&goldenInfo{Name: "", Area: 4294967295},
+		map[string]interface{}{"name": "gamma", "area": float64(7), "temp": 0.1, "tags": []interface{}{"c"}},
+	})
+	if err != nil {
+		t.Fatalf("GoldenVectors fail, err: %+v", err)
+	}
+
+	set := GoldenVectorSet{}
+	err = json.Unmarshal(data, &set)
+	if err != nil {
+		t.Fatalf("Unmarshal golden vectors fail, err: %+v", err)
+	}
+	if set.Name != "goldenInfo" || len(set.Vectors) != 3 {
+		t.Fatalf("golden vectors has name '%s' and %d vectors, expect: 'goldenInfo' and 3", set.Name, len(set.Vectors))
+	}
+	if typ := set.Definition.Properties["temp"].Type; typ != "float64le" {
+		t.Errorf("type of 'temp' property in definition: '%s', expect: 'float64le'", typ)
+	}
+	expValue := `{"area":1,"name":"alpha","tags":["a","b"],"temp":-2.5}`
+	var value bytes.Buffer
+	err = json.Compact(&value, set.Vectors[0].Value)
+	if err != nil {
+		t.Fatalf("Compact value of vector 0 fail, err: %+v", err)
+	}
+	if value.String() != expValue {
+		t.Errorf("value of vector 0: %s, expect: %s", value.String(), expValue)
+	}
+
+	err = VerifyGoldenVectors(data)
+	if err != nil {
+		t.Fatalf("VerifyGoldenVectors fail, err: %+v", err)
+	}
+
+	// tampered encoded data and value of the second vector
+	encoded := set.Vectors[1].Encoded
+	set.Vectors[1].Encoded = encoded[:len(encoded)-2] + "01"
+	assertGoldenVectorError(t, "tampered encoded data", &set, 1)
+
+	set.Vectors[1].Encoded = encoded
+	set.Vectors[1].Value = json.RawMessage(`{"area":4294967295,"name":"beta","tags":[],"temp":0}`)
+	assertGoldenVectorError(t, "tampered value", &set, 1)
+
+	set.Definition = nil
+	assertGoldenVectorError(t, "missing definition", &set, -1)
+
+	_, err = sch.GoldenVectors([]interface{}{map[string]interface{}{"name": 1}})
+	var goldenErr *GoldenVectorError
+	if !errors.As(err, &goldenErr) || goldenErr.Index != 0 {
+		t.Errorf("GoldenVectors with invalid sample should return GoldenVectorError of vector 0, got: %v", err)
+	}
+}
+
+func TestGoldenVectors64BitIntegers(t *testing.T) {
+	sch, err := NewJSONPack().AddSchema("bigInts", SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"u": {Type: "uint64le"},
+			"i": {Type: "int64be"},
+		},
+		Order: []string{"u", "i"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	data, err := sch.GoldenVectors([]interface{}{
+		map[string]interface{}{"u": uint64(1<<53 + 1), "i": int64(-1<<53 - 1)},
+		map[string]interface{}{"u": uint64(math.MaxUint64), "i": int64(math.MinInt64)},
+	})
+	if err != nil {
+		t.Fatalf("GoldenVectors fail, err: %+v", err)
+	}
+	err = VerifyGoldenVectors(data)
+	if err != nil {
+		t.Fatalf("VerifyGoldenVectors with 64-bit integers fail, err: %+v", err)
+	}
+
+	set := GoldenVectorSet{}
+	err = json.Unmarshal(data, &set)
+	if err != nil {
+		t.Fatalf("Unmarshal golden vectors fail, err: %+v", err)
+	}
+	set.Vectors[0].Value = json.RawMessage(`{"u":9007199254740992,"i":-9007199254740993}`)
+	assertGoldenVectorError(t, "value off by one", &set, 0)
+
+	set.Vectors[0].Value = json.RawMessage(`{"u":-1,"i":0}`)
+	assertGoldenVectorError(t, "negative unsigned value", &set, 0)
+}
+
+// TestGoldenVectorsFile verifies the committed golden vectors which are generated by jsonpack-ts tool,
+// the encoding format must not be changed unless the golden file is re-generated on purpose.
+func TestGoldenVectorsFile(t *testing.T) {
+	err := VerifyGoldenVectors(testdata.TypesGoldenData)
+	if err != nil {
+		t.Fatalf("VerifyGoldenVectors with types.golden.json fail, err: %+v", err)
+	}
+
+	set := GoldenVectorSet{}
+	err = json.Unmarshal(testdata.TypesGoldenData, &set)
+	if err != nil {
+		t.Fatalf("Unmarshal types.golden.json fail, err: %+v", err)
+	}
+	if len(set.Vectors) == 0 {
+		t.Fatalf("types.golden.json has no vectors")
+	}
+	if encoded := hex.EncodeToString(testdata.TypesExpData); set.Vectors[0].Encoded != encoded {
+		t.Errorf("encoded data of vector 0: %s, expect: %s", set.Vectors[0].Encoded, encoded)
+	}
+}
+
+func assertGoldenVectorError(t *testing.T, name string, set *GoldenVectorSet, index int) {
+	t.Helper()
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("%s: Marshal golden vectors fail, err: %+v", name, err)
+	}
+	err = VerifyGoldenVectors(data)
+	var goldenErr *GoldenVectorError
+	if !errors.As(err, &goldenErr) {
+		t.Errorf("%s: VerifyGoldenVectors should return GoldenVectorError, got: %v", name, err)
+		return
+	}
+	if goldenErr.Index != index {
+		t.Errorf("%s: index of GoldenVectorError: %d, expect: %d", name, goldenErr.Index, index)
+	}
+}
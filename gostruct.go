@@ -0,0 +1,202 @@
+package jsonpack
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+/*
+ToGoStruct converts schema definition def to Go source of type declarations, typeName is the name
+of top-level type, and the struct types can be added by AddSchema method with the same schema definition.
+
+The object types are converted to struct types with json tags of property names, the nested struct types are
+named by typeName followed by the upper camel case of property names, likes "InfoAddress" for "address"
+property of "Info" type. The array types are converted to slices, and the number types are converted to Go
+types with the same size, likes int16 for "int16le" type. The optional properties have "omitempty" option
+in json tags.
+
+It returns *ConvertError error with the property path if def is invalid, likes the property in order
+doesn't exist or the type is unknown.
+
+Example output of ToGoStruct(schDef, "Info"):
+	type Info struct {
+		Name    string      `json:"name"`
+		Area    uint32      `json:"area,omitempty"`
+		Tags    []string    `json:"tags"`
+		Address InfoAddress `json:"address"`
+	}
+
+	type InfoAddress struct {
+		Street string `json:"street"`
+	}
+*/
+func ToGoStruct(def *SchemaDef, typeName string) ([]byte, error) {
+	if !isGoIdent(typeName) {
+		return nil, errors.WithStack(&ConvertError{"", errors.Errorf("'%s' is not a valid Go type name", typeName)})
+	}
+
+	w := goStructWriter{names: make(map[string]bool)}
+	if def != nil && strings.ToLower(def.Type) == "object" {
+		_, err := w.goType(def, typeName, "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// the struct type of array items is named with "Item" suffix, e.g. type Infos []InfosItem
+		w.names[typeName] = true
+		typ, err := w.goType(def, typeName+"Item", "")
+		if err != nil {
+			return nil, err
+		}
+		w.decls = append([]goTypeDecl{{typeName, typ}}, w.decls...)
+	}
+
+	var b bytes.Buffer
+	for i, decl := range w.decls {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "type %s %s\n", decl.name, decl.typ)
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	return src, nil
+}
+
+type goTypeDecl struct {
+	name string
+	typ  string
+}
+
+// goStructWriter collects Go type declarations of schema definition.
+type goStructWriter struct {
+	decls []goTypeDecl
+	// declared type names
+	names map[string]bool
+}
+
+// goType returns Go type of def, and declares struct type if def is object type, the struct type
+// is named by name, or name followed by a number if name has been used.
+func (w *goStructWriter) goType(def *SchemaDef, name string, path string) (string, error) {
+	if def == nil {
+		return "", errors.WithStack(&ConvertError{path, errors.New("schema definition is nil")})
+	}
+
+	typ := strings.ToLower(def.Type)
+	switch typ {
+	case "object":
+		name = uniqueName(name, w.names)
+		w.names[name] = true
+		// reserves the position of declaration, nested struct types are declared after it
+		idx := len(w.decls)
+		w.decls = append(w.decls, goTypeDecl{name: name})
+
+		var b strings.Builder
+		b.WriteString("struct {\n")
+		fieldNames := make(map[string]bool, len(def.Order))
+		for _, propName := range def.Order {
+			propPath := formatPath(path, pathElem{name: propName})
+			prop, ok := def.Properties[propName]
+			if !ok {
+				return "", errors.WithStack(&ConvertError{propPath, errors.New("property in order doesn't exist")})
+			}
+
+			fieldName := uniqueName(upperCamelName(propName), fieldNames)
+			fieldNames[fieldName] = true
+			fieldType, err := w.goType(prop, name+fieldName, propPath)
+			if err != nil {
+				return "", err
+			}
+
+			tag := propName
+			if prop.Optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "%s %s `json:%s`\n", fieldName, fieldType, strconv.Quote(tag))
+		}
+		b.WriteString("}")
+		w.decls[idx].typ = b.String()
+		return name, nil
+
+	case "array":
+		itemType, err := w.goType(def.Items, name, path+"[]")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+
+	default:
+		opType, ok := builtinOpHandlerTypes[typ]
+		if !ok {
+			return "", errors.WithStack(&ConvertError{path, &UnknownTypeError{def.Type}})
+		}
+		switch opType {
+		case stringOpType:
+			return "string", nil
+		case booleanOpType:
+			return "bool", nil
+		}
+		typeName := opTypeName(opType)
+		return strings.TrimSuffix(strings.TrimSuffix(typeName, "le"), "be"), nil
+	}
+}
+
+// upperCamelName returns upper camel case identifier of property name, likes "UserId" for "user_id" property,
+// it's the field name of Go struct and the suffix of nested type name of Go and TypeScript.
+func upperCamelName(propName string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range propName {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if sb.Len() == 0 && !unicode.IsUpper(unicode.ToUpper(r)) {
+			// exported identifier starts with upper case letter
+			sb.WriteByte('F')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "Field"
+	}
+	return sb.String()
+}
+
+// uniqueName returns name if it's not used, or name followed by the smallest number which isn't used.
+func uniqueName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if n := name + strconv.Itoa(i); !used[n] {
+			return n
+		}
+	}
+}
+
+// isGoIdent reports whether s is a valid Go identifier.
+func isGoIdent(s string) bool {
+	if s == "" || token.IsKeyword(s) {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
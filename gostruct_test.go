@@ -0,0 +1,139 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestToGoStruct(t *testing.T) {
+	schDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":    {Type: "string"},
+			"area":    {Type: "uint32le", Optional: true},
+			"temp":    {Type: "Int16BE"},
+			"ratio":   {Type: "doublele"},
+			"active":  {Type: "bool"},
+			"user_id": {Type: "uint64be"},
+			"userId":  {Type: "int8"},
+			"2fa":     {Type: "boolean"},
+			"tags":    {Type: "array", Items: &SchemaDef{Type: "string"}},
+			"address": {
+				Type: "object",
+				Properties: map[string]*SchemaDef{
+					"street": {Type: "string"},
+					"geo": {
+						Type:       "object",
+						Properties: map[string]*SchemaDef{"lat": {Type: "float32le"}},
+						Order:      []string{"lat"},
+					},
+				},
+				Order: []string{"street", "geo"},
+			},
+			"accounts": {
+				Type: "array",
+				Items: &SchemaDef{
+					Type: "array",
+					Items: &SchemaDef{
+						Type:       "object",
+						Properties: map[string]*SchemaDef{"id": {Type: "uint16le"}},
+						Order:      []string{"id"},
+					},
+				},
+			},
+			"addressGeo": {
+				Type:       "object",
+				Properties: map[string]*SchemaDef{"x": {Type: "int64le"}},
+				Order:      []string{"x"},
+			},
+		},
+		Order: []string{"name", "area", "temp", "ratio", "active", "user_id", "userId", "2fa", "tags",
+			"address", "accounts", "addressGeo"},
+	}
+
+	src, err := ToGoStruct(schDef, "Info")
+	if err != nil {
+		t.Fatalf("ToGoStruct fail, err: %+v", err)
+	}
+	expSrc := "type Info struct {\n" +
+		"\tName       string           `json:\"name\"`\n" +
+		"\tArea       uint32           `json:\"area,omitempty\"`\n" +
+		"\tTemp       int16            `json:\"temp\"`\n" +
+		"\tRatio      float64          `json:\"ratio\"`\n" +
+		"\tActive     bool             `json:\"active\"`\n" +
+		"\tUserId     uint64           `json:\"user_id\"`\n" +
+		"\tUserId2    int8             `json:\"userId\"`\n" +
+		"\tF2fa       bool             `json:\"2fa\"`\n" +
+		"\tTags       []string         `json:\"tags\"`\n" +
+		"\tAddress    InfoAddress      `json:\"address\"`\n" +
+		"\tAccounts   [][]InfoAccounts `json:\"accounts\"`\n" +
+		"\tAddressGeo InfoAddressGeo2  `json:\"addressGeo\"`\n" +
+		"}\n" +
+		"\n" +
+		"type InfoAddress struct {\n" +
+		"\tStreet string         `json:\"street\"`\n" +
+		"\tGeo    InfoAddressGeo `json:\"geo\"`\n" +
+		"}\n" +
+		"\n" +
+		"type InfoAddressGeo struct {\n" +
+		"\tLat float32 `json:\"lat\"`\n" +
+		"}\n" +
+		"\n" +
+		"type InfoAccounts struct {\n" +
+		"\tId uint16 `json:\"id\"`\n" +
+		"}\n" +
+		"\n" +
+		"type InfoAddressGeo2 struct {\n" +
+		"\tX int64 `json:\"x\"`\n" +
+		"}\n"
+	if string(src) != expSrc {
+		t.Errorf("ToGoStruct result:\n%s\nexpect:\n%s", src, expSrc)
+	}
+
+	src, err = ToGoStruct(&SchemaDef{Type: "array", Items: &SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"name": {Type: "string"}},
+		Order:      []string{"name"},
+	}}, "Infos")
+	if err != nil {
+		t.Fatalf("ToGoStruct with array fail, err: %+v", err)
+	}
+	expSrc = "type Infos []InfosItem\n" +
+		"\n" +
+		"type InfosItem struct {\n" +
+		"\tName string `json:\"name\"`\n" +
+		"}\n"
+	if string(src) != expSrc {
+		t.Errorf("ToGoStruct with array result:\n%s\nexpect:\n%s", src, expSrc)
+	}
+}
+
+func TestToGoStructError(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      *SchemaDef
+		typeName string
+		path     string
+	}{
+		{"invalid type name", &SchemaDef{Type: "string"}, "type", ""},
+		{"unknown type", &SchemaDef{
+			Type:       "object",
+			Properties: map[string]*SchemaDef{"a": {Type: "array", Items: &SchemaDef{Type: "int128"}}},
+			Order:      []string{"a"},
+		}, "Info", "a[]"},
+		{"missing property", &SchemaDef{Type: "object", Properties: map[string]*SchemaDef{}, Order: []string{"a"}}, "Info", "a"},
+		{"missing items", &SchemaDef{Type: "array"}, "Info", "[]"},
+	}
+	for _, test := range tests {
+		_, err := ToGoStruct(test.def, test.typeName)
+		var convertErr *ConvertError
+		if !errors.As(err, &convertErr) {
+			t.Errorf("%s: ToGoStruct should return ConvertError, got: %v", test.name, err)
+			continue
+		}
+		if convertErr.Path != test.path {
+			t.Errorf("%s: path of ConvertError: '%s', expect: '%s'", test.name, convertErr.Path, test.path)
+		}
+	}
+}
@@ -47,6 +47,7 @@ const (
 	float32BEOpType
 	float64LEOpType
 	float64BEOpType
+	skipOpType
 )
 
 // Null type
@@ -65,10 +66,27 @@ func (p *nullOp) decodeDynamic(buf *ibuf.Buffer, opNode *operation, v interface{
 	return nil, errors.WithStack(&NotImplementedError{"nullOp.decodeDynamic"})
 }
 
+// Skip type, skips encoded value of property which doesn't exist in struct in projection decoding
+type skipOp struct{}
+
+func (p *skipOp) decodeStruct(buf *ibuf.Buffer, opNode *structOperation, ptr unsafe.Pointer) error {
+	return skipValue(buf, opNode.opInstance)
+}
+func (p *skipOp) encodeStruct(buf *ibuf.Buffer, opNode *structOperation, ptr unsafe.Pointer) error {
+	return errors.WithStack(&NotImplementedError{"skipOp.encodeStruct"})
+}
+func (p *skipOp) encodeDynamic(buf *ibuf.Buffer, opNode *operation, data interface{}) error {
+	return errors.WithStack(&NotImplementedError{"skipOp.encodeDynamic"})
+}
+func (p *skipOp) decodeDynamic(buf *ibuf.Buffer, opNode *operation, v interface{}) (interface{}, error) {
+	return nil, skipValue(buf, opNode)
+}
+
 // declare operation variables for type alias
 var (
 	_nullOp   = &nullOp{}
 	_structOp = &structOp{}
+	_skipOp   = &skipOp{}
 	// _objectOp    = &objectOp{}
 	_sliceOp     = &sliceOp{}
 	_arrayOp     = &arrayOp{}
@@ -135,6 +153,60 @@ var builtinOpHandlerTypes = map[string]opHandlerType{
 	"float64le": float64LEOpType,
 }
 
+// opTypeNames maps handler types to canonical type names of schema definition
+var opTypeNames = map[opHandlerType]string{
+	objectOpType:    "object",
+	structOpType:    "object",
+	sliceOpType:     "array",
+	arrayOpType:     "array",
+	booleanOpType:   "boolean",
+	stringOpType:    "string",
+	int8OpType:      "int8",
+	int16LEOpType:   "int16le",
+	int16BEOpType:   "int16be",
+	int32LEOpType:   "int32le",
+	int32BEOpType:   "int32be",
+	int64LEOpType:   "int64le",
+	int64BEOpType:   "int64be",
+	uint8OpType:     "uint8",
+	uint16LEOpType:  "uint16le",
+	uint16BEOpType:  "uint16be",
+	uint32LEOpType:  "uint32le",
+	uint32BEOpType:  "uint32be",
+	uint64LEOpType:  "uint64le",
+	uint64BEOpType:  "uint64be",
+	float32LEOpType: "float32le",
+	float32BEOpType: "float32be",
+	float64LEOpType: "float64le",
+	float64BEOpType: "float64be",
+}
+
+// opTypeName returns canonical type name of handler type
+func opTypeName(typ opHandlerType) string {
+	name, ok := opTypeNames[typ]
+	if !ok {
+		return "null"
+	}
+	return name
+}
+
+// builtinFixedSize returns byte size of encoded value of builtin handler type,
+// or returns -1 if the type is variable length or not a builtin type.
+func builtinFixedSize(typ opHandlerType) int64 {
+	switch typ {
+	case booleanOpType, int8OpType, uint8OpType:
+		return 1
+	case int16LEOpType, int16BEOpType, uint16LEOpType, uint16BEOpType:
+		return 2
+	case int32LEOpType, int32BEOpType, uint32LEOpType, uint32BEOpType, float32LEOpType, float32BEOpType:
+		return 4
+	case int64LEOpType, int64BEOpType, uint64LEOpType, uint64BEOpType, float64LEOpType, float64BEOpType:
+		return 8
+	default:
+		return -1
+	}
+}
+
 func isBuiltinType(propType *string) bool {
 	_, ok := builtinTypes[*propType]
 	return ok
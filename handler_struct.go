@@ -15,6 +15,15 @@ func (p *structOp) decodeStruct(buf *ibuf.Buffer, opNode *structOperation, ptr u
 	var fieldPtr unsafe.Pointer
 
 	for _, op := range opNode.children {
+		// property doesn't exist in struct, skip it
+		if op.handlerType == skipOpType {
+			err = op.handler.decodeStruct(buf, op, nil)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		fieldPtr = op.field.UnsafeGet(ptr)
 
 		if op.isPtrType {
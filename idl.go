@@ -0,0 +1,517 @@
+package jsonpack
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IDLMessage represents a message declaration of schema IDL.
+type IDLMessage struct {
+	// message name
+	Name string
+	// schema definition of message
+	Def *SchemaDef
+}
+
+/*
+ParseIDL parses schema IDL src and returns the schema definitions of declared messages in the
+order of declarations.
+
+The schema IDL is a compact form of schema definition, the properties are declared in the order
+of encoding, so there is no separate order list to maintain. The syntax is:
+
+	// comments start with "//" and end at the end of line
+	message Info {
+		name: string;
+		area?: uint32le;        // "?" marks optional property
+		tags: [string];         // array of string
+		address: {              // object
+			street: string;
+			"zip-code": string; // quotes the name which isn't an identifier
+		};
+		phones: [Phone];        // array of the object declared by Phone message
+	}
+
+	message Phone { area: uint16le; number: string }
+
+	// array as top-level type
+	message Points [{ x: int16le; y: int16le }]
+
+The properties are separated by ";", and the last ";" in object is optional. The types are builtin
+types likes "string" and "uint32le", arrays likes "[string]", objects likes "{ name: string }", or the names of
+messages which are declared as objects in src, the messages can be declared in any order.
+
+It returns *IDLSyntaxError error with line and column numbers if src is invalid.
+
+Example:
+	messages, err := jsonpack.ParseIDL(src)
+	for _, msg := range messages {
+		_, err = jsonPack.AddSchema(msg.Name, *msg.Def)
+	}
+*/
+func ParseIDL(src []byte) ([]IDLMessage, error) {
+	p := idlParser{lexer: idlLexer{src: src, line: 1, col: 1}}
+	return p.parse()
+}
+
+type idlTokenKind uint8
+
+const (
+	idlEOF idlTokenKind = iota
+	idlIdent
+	idlString
+	idlPunct
+)
+
+type idlToken struct {
+	kind idlTokenKind
+	// identifier, unquoted string or punctuation
+	text string
+	line int
+	col  int
+}
+
+func (t idlToken) String() string {
+	switch t.kind {
+	case idlEOF:
+		return "end of input"
+	case idlString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+// idlLexer splits schema IDL into tokens.
+type idlLexer struct {
+	src  []byte
+	pos  int
+	line int
+	col  int
+}
+
+func (l *idlLexer) advance() {
+	if l.src[l.pos] == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	l.pos++
+}
+
+func (l *idlLexer) next() (idlToken, error) {
+	// skip spaces and comments
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			l.advance()
+		} else if c == '/' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '/' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance()
+			}
+		} else {
+			break
+		}
+	}
+
+	tok := idlToken{line: l.line, col: l.col}
+	if l.pos >= len(l.src) {
+		tok.kind = idlEOF
+		return tok, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case isIDLIdentByte(c, true):
+		for l.pos < len(l.src) && isIDLIdentByte(l.src[l.pos], false) {
+			l.advance()
+		}
+		tok.kind = idlIdent
+		tok.text = string(l.src[start:l.pos])
+
+	case c == '"':
+		l.advance()
+		for l.pos < len(l.src) && l.src[l.pos] != '"' && l.src[l.pos] != '\n' {
+			if l.src[l.pos] == '\\' && l.pos+1 < len(l.src) {
+				l.advance()
+			}
+			l.advance()
+		}
+		if l.pos >= len(l.src) || l.src[l.pos] != '"' {
+			return tok, tok.errorf("unterminated string")
+		}
+		l.advance()
+		str, err := strconv.Unquote(string(l.src[start:l.pos]))
+		if err != nil {
+			return tok, tok.errorf("invalid string %s", l.src[start:l.pos])
+		}
+		tok.kind = idlString
+		tok.text = str
+
+	case strings.IndexByte("{}[]:;?", c) >= 0:
+		l.advance()
+		tok.kind = idlPunct
+		tok.text = string(c)
+
+	default:
+		return tok, tok.errorf("unexpected character %q", c)
+	}
+	return tok, nil
+}
+
+func isIDLIdentByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func (t idlToken) errorf(format string, args ...interface{}) error {
+	return errors.WithStack(&IDLSyntaxError{t.line, t.col, fmt.Sprintf(format, args...)})
+}
+
+// idlRef represents a reference to message which is resolved after parsing.
+type idlRef struct {
+	tok    idlToken
+	schDef *SchemaDef
+}
+
+// idlParser parses schema IDL into schema definitions.
+type idlParser struct {
+	lexer idlLexer
+	tok   idlToken
+	// references to messages, the Type of schema definition is the message name before resolving
+	refs []idlRef
+}
+
+func (p *idlParser) nextToken() error {
+	var err error
+	p.tok, err = p.lexer.next()
+	return err
+}
+
+func (p *idlParser) isPunct(punct string) bool {
+	return p.tok.kind == idlPunct && p.tok.text == punct
+}
+
+// expect consumes punctuation punct, it returns error if current token isn't punct.
+func (p *idlParser) expect(punct string) error {
+	if !p.isPunct(punct) {
+		return p.tok.errorf("expected '%s', got %s", punct, p.tok)
+	}
+	return p.nextToken()
+}
+
+func (p *idlParser) parse() ([]IDLMessage, error) {
+	messages := make([]IDLMessage, 0)
+	msgTokens := make(map[string]idlToken)
+
+	err := p.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind != idlEOF {
+		if p.tok.kind != idlIdent || p.tok.text != "message" {
+			return nil, p.tok.errorf("expected 'message', got %s", p.tok)
+		}
+		if err = p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		nameTok := p.tok
+		if nameTok.kind != idlIdent {
+			return nil, nameTok.errorf("expected message name, got %s", nameTok)
+		}
+		if _, exist := msgTokens[nameTok.text]; exist {
+			return nil, nameTok.errorf("message '%s' is declared more than once", nameTok.text)
+		}
+		msgTokens[nameTok.text] = nameTok
+		if err = p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		var schDef *SchemaDef
+		switch {
+		case p.isPunct("{"):
+			schDef, err = p.parseObject()
+		case p.isPunct("["):
+			schDef, err = p.parseArray()
+		default:
+			err = p.tok.errorf("expected '{' or '[' after message name, got %s", p.tok)
+		}
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, IDLMessage{Name: nameTok.text, Def: schDef})
+	}
+
+	err = p.resolveRefs(messages)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// parseType parses type of property or array items.
+func (p *idlParser) parseType() (*SchemaDef, error) {
+	switch {
+	case p.isPunct("{"):
+		return p.parseObject()
+	case p.isPunct("["):
+		return p.parseArray()
+	case p.tok.kind == idlIdent:
+		schDef := &SchemaDef{Type: p.tok.text}
+		typ := strings.ToLower(p.tok.text)
+		if !isBuiltinType(&typ) {
+			p.refs = append(p.refs, idlRef{p.tok, schDef})
+		}
+		return schDef, p.nextToken()
+	}
+	return nil, p.tok.errorf("expected type, got %s", p.tok)
+}
+
+func (p *idlParser) parseArray() (*SchemaDef, error) {
+	err := p.expect("[")
+	if err != nil {
+		return nil, err
+	}
+	itemDef, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expect("]")
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaDef{Type: "array", Items: itemDef}, nil
+}
+
+func (p *idlParser) parseObject() (*SchemaDef, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	schDef := &SchemaDef{
+		Type:       "object",
+		Properties: make(map[string]*SchemaDef),
+		Order:      make([]string, 0),
+	}
+	for !p.isPunct("}") {
+		nameTok := p.tok
+		if nameTok.kind != idlIdent && nameTok.kind != idlString {
+			return nil, nameTok.errorf("expected property name, got %s", nameTok)
+		}
+		if _, exist := schDef.Properties[nameTok.text]; exist {
+			return nil, nameTok.errorf("property '%s' is declared more than once", nameTok.text)
+		}
+		if err = p.nextToken(); err != nil {
+			return nil, err
+		}
+
+		optional := p.isPunct("?")
+		if optional {
+			if err = p.nextToken(); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		propDef, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		propDef.Optional = optional
+		schDef.Properties[nameTok.text] = propDef
+		schDef.Order = append(schDef.Order, nameTok.text)
+
+		if p.isPunct(";") {
+			if err = p.nextToken(); err != nil {
+				return nil, err
+			}
+		} else if !p.isPunct("}") {
+			return nil, p.tok.errorf("expected ';' or '}' after property, got %s", p.tok)
+		}
+	}
+	return schDef, p.nextToken()
+}
+
+// resolveRefs replaces the references to messages with the schema definitions of messages.
+func (p *idlParser) resolveRefs(messages []IDLMessage) error {
+	defs := make(map[string]*SchemaDef, len(messages))
+	for _, msg := range messages {
+		defs[msg.Name] = msg.Def
+	}
+
+	// the references are resolved in order, the message which refers to itself directly or
+	// indirectly is detected by the number of resolving steps
+	for _, ref := range p.refs {
+		msgDef, ok := defs[ref.tok.text]
+		if !ok {
+			return ref.tok.errorf("unknown type '%s'", ref.tok.text)
+		}
+		if msgDef.Type != "object" {
+			return ref.tok.errorf("message '%s' is not an object", ref.tok.text)
+		}
+	}
+
+	resolved := make(map[*SchemaDef]bool, len(p.refs))
+	for range p.refs {
+		progress := false
+		for _, ref := range p.refs {
+			if resolved[ref.schDef] {
+				continue
+			}
+			msgDef := defs[ref.tok.text]
+			if p.hasUnresolvedRef(msgDef, resolved) {
+				continue
+			}
+			optional := ref.schDef.Optional
+			*ref.schDef = *cloneSchemaDef(msgDef)
+			ref.schDef.Optional = optional
+			resolved[ref.schDef] = true
+			progress = true
+		}
+		if !progress {
+			break
+		}
+	}
+	for _, ref := range p.refs {
+		if !resolved[ref.schDef] {
+			return ref.tok.errorf("recursive reference to message '%s' is not supported", ref.tok.text)
+		}
+	}
+	return nil
+}
+
+// hasUnresolvedRef reports whether schema definition has unresolved references to messages.
+func (p *idlParser) hasUnresolvedRef(schDef *SchemaDef, resolved map[*SchemaDef]bool) bool {
+	for _, ref := range p.refs {
+		if !resolved[ref.schDef] && containsSchemaDef(schDef, ref.schDef) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSchemaDef reports whether target is schDef or one of its properties or items.
+func containsSchemaDef(schDef *SchemaDef, target *SchemaDef) bool {
+	if schDef == nil {
+		return false
+	}
+	if schDef == target {
+		return true
+	}
+	if containsSchemaDef(schDef.Items, target) {
+		return true
+	}
+	for _, prop := range schDef.Properties {
+		if containsSchemaDef(prop, target) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+FormatIDL returns schema IDL of message name with schema definition def, the IDL can be parsed by
+ParseIDL function.
+
+It returns *ConvertError error with the property path if def is invalid, likes the property in order
+doesn't exist or the type is unknown, or with empty path if name isn't a valid identifier.
+
+Example output of FormatIDL("Info", schDef):
+	message Info {
+	  name: string;
+	  area?: uint32le;
+	  tags: [string];
+	  address: {
+	    street: string;
+	  };
+	}
+*/
+func FormatIDL(name string, def *SchemaDef) ([]byte, error) {
+	if !isIDLIdent(name) {
+		return nil, errors.WithStack(&ConvertError{"", errors.Errorf("message name '%s' is not a valid identifier", name)})
+	}
+
+	var b bytes.Buffer
+	b.WriteString("message ")
+	b.WriteString(name)
+	b.WriteByte(' ')
+	err := writeIDLType(&b, def, "", "")
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// writeIDLType writes IDL type of def to b, path is the property path of def.
+func writeIDLType(b *bytes.Buffer, def *SchemaDef, indent string, path string) error {
+	if def == nil {
+		return errors.WithStack(&ConvertError{path, errors.New("schema definition is nil")})
+	}
+
+	switch strings.ToLower(def.Type) {
+	case "object":
+		b.WriteString("{\n")
+		for _, propName := range def.Order {
+			propPath := formatPath(path, pathElem{name: propName})
+			prop, ok := def.Properties[propName]
+			if !ok {
+				return errors.WithStack(&ConvertError{propPath, errors.New("property in order doesn't exist")})
+			}
+
+			b.WriteString(indent + "  ")
+			if isIDLIdent(propName) {
+				b.WriteString(propName)
+			} else {
+				b.WriteString(strconv.Quote(propName))
+			}
+			if prop.Optional {
+				b.WriteByte('?')
+			}
+			b.WriteString(": ")
+			err := writeIDLType(b, prop, indent+"  ", propPath)
+			if err != nil {
+				return err
+			}
+			b.WriteString(";\n")
+		}
+		b.WriteString(indent + "}")
+
+	case "array":
+		b.WriteByte('[')
+		err := writeIDLType(b, def.Items, indent, path+"[]")
+		if err != nil {
+			return err
+		}
+		b.WriteByte(']')
+
+	default:
+		typ := strings.ToLower(def.Type)
+		if !isBuiltinType(&typ) {
+			return errors.WithStack(&ConvertError{path, &UnknownTypeError{def.Type}})
+		}
+		b.WriteString(def.Type)
+	}
+	return nil
+}
+
+// isIDLIdent reports whether s is an identifier of schema IDL.
+func isIDLIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIDLIdentByte(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package jsonpack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseIDL(t *testing.T) {
+	src := []byte(`// user information
+message Info {
+	name: string;
+	area?: uint32le; // optional
+	tags: [string];
+	address: {
+		street: string;
+		"zip-code": string
+	};
+	phones: [Phone];
+	primary?: Phone
+}
+
+message Phone { area: uint16le; number: string }
+
+message Points [{ x: int16le; y: int16le }]
+`)
+
+	messages, err := ParseIDL(src)
+	if err != nil {
+		t.Fatalf("ParseIDL fail, err: %+v", err)
+	}
+
+	phoneDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"area":   {Type: "uint16le"},
+			"number": {Type: "string"},
+		},
+		Order: []string{"area", "number"},
+	}
+	primaryDef := cloneSchemaDef(phoneDef)
+	primaryDef.Optional = true
+	expMessages := []IDLMessage{
+		{Name: "Info", Def: &SchemaDef{
+			Type: "object",
+			Properties: map[string]*SchemaDef{
+				"name": {Type: "string"},
+				"area": {Type: "uint32le", Optional: true},
+				"tags": {Type: "array", Items: &SchemaDef{Type: "string"}},
+				"address": {
+					Type: "object",
+					Properties: map[string]*SchemaDef{
+						"street":   {Type: "string"},
+						"zip-code": {Type: "string"},
+					},
+					Order: []string{"street", "zip-code"},
+				},
+				"phones":  {Type: "array", Items: phoneDef},
+				"primary": primaryDef,
+			},
+			Order: []string{"name", "area", "tags", "address", "phones", "primary"},
+		}},
+		{Name: "Phone", Def: phoneDef},
+		{Name: "Points", Def: &SchemaDef{Type: "array", Items: &SchemaDef{
+			Type: "object",
+			Properties: map[string]*SchemaDef{
+				"x": {Type: "int16le"},
+				"y": {Type: "int16le"},
+			},
+			Order: []string{"x", "y"},
+		}}},
+	}
+	if !reflect.DeepEqual(messages, expMessages) {
+		t.Errorf("ParseIDL result: %+v, expect: %+v", messages, expMessages)
+	}
+
+	for _, msg := range messages {
+		if _, err = NewJSONPack().AddSchema(msg.Name, *msg.Def); err != nil {
+			t.Errorf("AddSchema with parsed schema definition of '%s' fail, err: %+v", msg.Name, err)
+		}
+	}
+
+	invalidSrcs := []struct {
+		src    string
+		line   int
+		column int
+	}{
+		{"message A { a: string", 1, 22},
+		{"message A {\n  a string;\n}", 2, 5},
+		{"message A {\n  a: string;\n  a: int8;\n}", 3, 3},
+		{"message A { a: Unknown }", 1, 16},
+		{"message A { b: B }\nmessage B { a: [A] }", 1, 16},
+		{"message A { a: string }\nmessage A { b: string }", 2, 9},
+		{"message A { a: string; b: # }", 1, 27},
+		{"message A { \"a: string }", 1, 13},
+		{"info A { a: string }", 1, 1},
+	}
+	for _, c := range invalidSrcs {
+		var syntaxErr *IDLSyntaxError
+		_, err = ParseIDL([]byte(c.src))
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != c.line || syntaxErr.Column != c.column {
+			t.Errorf("ParseIDL %q should fail at line %d, column %d, err: %v", c.src, c.line, c.column, err)
+		}
+	}
+}
+
+func TestFormatIDL(t *testing.T) {
+	schDef, err := jsonPack.GetSchema("complex").GetSchemaDef()
+	if err != nil {
+		t.Fatalf("GetSchemaDef fail, err: %+v", err)
+	}
+	schDef.Properties["category"].Optional = true
+	schDef.Properties["a-b"] = &SchemaDef{Type: "array", Items: &SchemaDef{Type: "array", Items: &SchemaDef{Type: "int8"}}}
+	schDef.Order = append(schDef.Order, "a-b")
+
+	idl, err := FormatIDL("Complex", schDef)
+	if err != nil {
+		t.Fatalf("FormatIDL fail, err: %+v", err)
+	}
+
+	messages, err := ParseIDL(idl)
+	if err != nil {
+		t.Fatalf("ParseIDL of formatted IDL fail, err: %+v\n%s", err, idl)
+	}
+	if len(messages) != 1 || messages[0].Name != "Complex" || !reflect.DeepEqual(messages[0].Def, schDef) {
+		t.Errorf("round-trip schema definition mismatch\n%s", idl)
+	}
+
+	idl, err = FormatIDL("Info", &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":    {Type: "string"},
+			"area":    {Type: "uint32le", Optional: true},
+			"address": {Type: "object", Properties: map[string]*SchemaDef{"street": {Type: "string"}}, Order: []string{"street"}},
+		},
+		Order: []string{"name", "area", "address"},
+	})
+	if err != nil {
+		t.Fatalf("FormatIDL fail, err: %+v", err)
+	}
+	expIDL := `message Info {
+  name: string;
+  area?: uint32le;
+  address: {
+    street: string;
+  };
+}
+`
+	if string(idl) != expIDL {
+		t.Errorf("FormatIDL result:\n%s\nexpect:\n%s", idl, expIDL)
+	}
+
+	var convertErr *ConvertError
+	_, err = FormatIDL("Info", &SchemaDef{Type: "object", Properties: map[string]*SchemaDef{}, Order: []string{"a"}})
+	if !errors.As(err, &convertErr) || convertErr.Path != "a" {
+		t.Errorf("FormatIDL with missing property should fail, err: %v", err)
+	}
+	_, err = FormatIDL("my-msg", &SchemaDef{Type: "object", Properties: map[string]*SchemaDef{"a": {Type: "string"}}, Order: []string{"a"}})
+	if !errors.As(err, &convertErr) || convertErr.Path != "" {
+		t.Errorf("FormatIDL with invalid message name should fail, err: %v", err)
+	}
+}
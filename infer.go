@@ -0,0 +1,281 @@
+package jsonpack
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+type inferKind uint8
+
+const (
+	// only null values observed
+	inferUnknown inferKind = iota
+	inferBoolean
+	inferString
+	inferNumber
+	inferObject
+	inferArray
+)
+
+var inferKindNames = map[inferKind]string{
+	inferUnknown: "null",
+	inferBoolean: "boolean",
+	inferString:  "string",
+	inferNumber:  "number",
+	inferObject:  "object",
+	inferArray:   "array",
+}
+
+// inferNode holds the observed values of a property or array items.
+type inferNode struct {
+	kind inferKind
+	// number of observed values that are not null
+	present int
+	// null value observed
+	nullable bool
+
+	// non-integer number observed
+	isFloat bool
+	// minimum of observed integers, it's less than zero only if negative integer observed
+	minInt int64
+	// maximum of observed integers
+	maxUint uint64
+
+	// number of observed objects
+	objects int
+	props   map[string]*inferNode
+	order   []string
+
+	items *inferNode
+}
+
+/*
+InferSchema unifies JSON documents of samples into a schema definition, it's useful for
+writing schema definitions of existing JSON feeds.
+
+The rules of inferring are:
+
+* The type of top-level value needs to be object or array.
+
+* The properties of object are ordered by the order of first occurrence in samples.
+
+* The integer property uses the narrowest integer type that fits all observed values, likes "uint8"
+for values between 0 and 255, and "int16le" for values between -32768 and 32767. The number property
+which has non-integer values uses "float64le" type.
+
+* The items of array are unified as a whole, the array of objects has items of object type
+with the properties of all objects.
+
+* The property which is missing or null in some samples is marked as optional.
+
+The narrowest integer type only fits the observed values, use samples which cover the range of values,
+or widen the integer types of result if necessary.
+
+It returns *InferError error if the sample is invalid JSON document, the types of a property
+conflict in samples, or the type of property can't be inferred, likes the property only has null
+values or the array is always empty.
+
+Example:
+	schDef, err := jsonpack.InferSchema(sample1, sample2)
+	sch, err := jsonPack.AddSchema("Feed", *schDef)
+*/
+func InferSchema(samples ...[]byte) (*SchemaDef, error) {
+	if len(samples) == 0 {
+		return nil, errors.WithStack(&InferError{-1, "", errors.New("no sample")})
+	}
+
+	root := &inferNode{}
+	for i, sample := range samples {
+		v, err := parseOrderedJSON(sample)
+		if err != nil {
+			return nil, errors.WithStack(&InferError{i, "", err})
+		}
+		switch v.(type) {
+		case *orderedObject, []interface{}:
+		default:
+			return nil, errors.WithStack(&InferError{i, "", errors.New("top-level value needs to be an object or an array")})
+		}
+
+		path, err := root.merge(v, "")
+		if err != nil {
+			return nil, errors.WithStack(&InferError{i, path, err})
+		}
+	}
+
+	path, schDef, err := root.schemaDef("")
+	if err != nil {
+		return nil, errors.WithStack(&InferError{-1, path, err})
+	}
+	return schDef, nil
+}
+
+// setKind sets the kind of node, it returns error if the node has a different kind.
+func (n *inferNode) setKind(kind inferKind) error {
+	if n.kind == inferUnknown {
+		n.kind = kind
+	} else if n.kind != kind {
+		return errors.Errorf("conflicting types %s and %s", inferKindNames[n.kind], inferKindNames[kind])
+	}
+	return nil
+}
+
+// merge merges observed value v into node, it returns the property path where error occurs.
+func (n *inferNode) merge(v interface{}, path string) (string, error) {
+	if v == nil {
+		n.nullable = true
+		return "", nil
+	}
+	n.present++
+
+	var err error
+	switch v := v.(type) {
+	case bool:
+		err = n.setKind(inferBoolean)
+
+	case string:
+		err = n.setKind(inferString)
+
+	case json.Number:
+		err = n.setKind(inferNumber)
+		if err != nil {
+			break
+		}
+		if i, e := strconv.ParseInt(v.String(), 10, 64); e == nil {
+			if i < n.minInt {
+				n.minInt = i
+			}
+			if i > 0 && uint64(i) > n.maxUint {
+				n.maxUint = uint64(i)
+			}
+		} else if u, e := strconv.ParseUint(v.String(), 10, 64); e == nil {
+			if u > n.maxUint {
+				n.maxUint = u
+			}
+		} else {
+			n.isFloat = true
+		}
+
+	case *orderedObject:
+		err = n.setKind(inferObject)
+		if err != nil {
+			break
+		}
+		n.objects++
+		if n.props == nil {
+			n.props = make(map[string]*inferNode)
+		}
+		for _, key := range v.keys {
+			child, ok := n.props[key]
+			if !ok {
+				child = &inferNode{}
+				n.props[key] = child
+				n.order = append(n.order, key)
+			}
+			childPath := formatPath(path, pathElem{name: key})
+			if errPath, err := child.merge(v.values[key], childPath); err != nil {
+				return errPath, err
+			}
+		}
+
+	case []interface{}:
+		err = n.setKind(inferArray)
+		if err != nil {
+			break
+		}
+		if n.items == nil {
+			n.items = &inferNode{}
+		}
+		for i, item := range v {
+			itemPath := formatPath(path, pathElem{index: uint64(i), isIndex: true})
+			if errPath, err := n.items.merge(item, itemPath); err != nil {
+				return errPath, err
+			}
+		}
+	}
+
+	if err != nil {
+		return path, err
+	}
+	return "", nil
+}
+
+// schemaDef returns schema definition of node, and returns the property path where error occurs.
+func (n *inferNode) schemaDef(path string) (string, *SchemaDef, error) {
+	switch n.kind {
+	case inferBoolean:
+		return "", &SchemaDef{Type: "boolean"}, nil
+
+	case inferString:
+		return "", &SchemaDef{Type: "string"}, nil
+
+	case inferNumber:
+		typ, err := n.numberType()
+		if err != nil {
+			return path, nil, err
+		}
+		return "", &SchemaDef{Type: typ}, nil
+
+	case inferObject:
+		schDef := &SchemaDef{
+			Type:       "object",
+			Properties: make(map[string]*SchemaDef),
+			Order:      n.order,
+		}
+		for _, key := range n.order {
+			child := n.props[key]
+			errPath, childDef, err := child.schemaDef(formatPath(path, pathElem{name: key}))
+			if err != nil {
+				return errPath, nil, err
+			}
+			childDef.Optional = child.nullable || child.present < n.objects
+			schDef.Properties[key] = childDef
+		}
+		return "", schDef, nil
+
+	case inferArray:
+		if n.items == nil || n.items.kind == inferUnknown {
+			return path, nil, errors.New("type of array items can't be inferred from empty arrays or null items")
+		}
+		errPath, itemDef, err := n.items.schemaDef(path + "[]")
+		if err != nil {
+			return errPath, nil, err
+		}
+		return "", &SchemaDef{Type: "array", Items: itemDef}, nil
+	}
+	return path, nil, errors.New("type can't be inferred from null values")
+}
+
+// numberType returns the narrowest number type that fits observed values.
+func (n *inferNode) numberType() (string, error) {
+	if n.isFloat {
+		return "float64le", nil
+	}
+
+	if n.minInt >= 0 {
+		switch {
+		case n.maxUint <= math.MaxUint8:
+			return "uint8", nil
+		case n.maxUint <= math.MaxUint16:
+			return "uint16le", nil
+		case n.maxUint <= math.MaxUint32:
+			return "uint32le", nil
+		default:
+			return "uint64le", nil
+		}
+	}
+
+	switch {
+	case n.minInt >= math.MinInt8 && n.maxUint <= math.MaxInt8:
+		return "int8", nil
+	case n.minInt >= math.MinInt16 && n.maxUint <= math.MaxInt16:
+		return "int16le", nil
+	case n.minInt >= math.MinInt32 && n.maxUint <= math.MaxInt32:
+		return "int32le", nil
+	case n.maxUint <= math.MaxInt64:
+		return "int64le", nil
+	}
+	return "", errors.Errorf("integers from %d to %d exceed the range of integer types", n.minInt, n.maxUint)
+}
@@ -0,0 +1,95 @@
+package jsonpack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestInferSchema(t *testing.T) {
+	samples := [][]byte{
+		[]byte(`{"id": 1, "name": "a", "score": -3, "tags": ["x"], "items": [{"sku": "s1", "qty": 2}], "ratio": 1}`),
+		[]byte(`{"id": 70000, "name": "b", "score": 200, "tags": [], "items": [{"sku": "s2", "qty": 1, "note": null}], "ratio": 0.5, "extra": true}`),
+	}
+	// "note" only has null value
+	var expectErr *InferError
+	_, err := InferSchema(samples...)
+	if !errors.As(err, &expectErr) || expectErr.Path != "items[].note" {
+		t.Errorf("InferSchema null only property should fail, err: %v", err)
+	}
+
+	samples = append(samples, []byte(`{"id": 3, "name": "c", "score": 0, "tags": [], "items": [{"sku": "s3", "qty": 3, "note": "n"}], "ratio": 2}`))
+	schDef, err := InferSchema(samples...)
+	if err != nil {
+		t.Fatalf("InferSchema fail, err: %+v", err)
+	}
+
+	expDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"id":    {Type: "uint32le"},
+			"name":  {Type: "string"},
+			"score": {Type: "int16le"},
+			"tags":  {Type: "array", Items: &SchemaDef{Type: "string"}},
+			"items": {Type: "array", Items: &SchemaDef{
+				Type: "object",
+				Properties: map[string]*SchemaDef{
+					"sku":  {Type: "string"},
+					"qty":  {Type: "uint8"},
+					"note": {Type: "string", Optional: true},
+				},
+				Order: []string{"sku", "qty", "note"},
+			}},
+			"ratio": {Type: "float64le"},
+			"extra": {Type: "boolean", Optional: true},
+		},
+		Order: []string{"id", "name", "score", "tags", "items", "ratio", "extra"},
+	}
+	if !reflect.DeepEqual(schDef, expDef) {
+		t.Errorf("InferSchema, expect: %+v, got: %+v", expDef, schDef)
+	}
+
+	// the inferred schema can be compiled and encodes samples
+	sch, err := NewJSONPack().AddSchema("inferred", *schDef)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	_, err = sch.Encode(map[string]interface{}{
+		"id": uint32(1), "name": "a", "score": int16(-3), "tags": []interface{}{"x"},
+		"items": []interface{}{map[string]interface{}{"sku": "s1", "qty": uint8(2), "note": ""}},
+		"ratio": 1.0, "extra": false,
+	})
+	if err != nil {
+		t.Errorf("Encode with inferred schema fail, err: %+v", err)
+	}
+
+	// array of objects at top-level
+	schDef, err = InferSchema([]byte(`[{"a": 1}, {"a": 2, "b": "x"}]`))
+	if err != nil {
+		t.Fatalf("InferSchema fail, err: %+v", err)
+	}
+	if schDef.Type != "array" || schDef.Items.Type != "object" || !schDef.Items.Properties["b"].Optional {
+		t.Errorf("InferSchema array, got: %+v", schDef)
+	}
+
+	invalidSamples := [][][]byte{
+		{},
+		{[]byte(`"string"`)},
+		{[]byte(`{"a": 1`)},
+		{[]byte(`{"a": 1}`), []byte(`{"a": "1"}`)},
+		{[]byte(`{"a": null}`)},
+		{[]byte(`{"a": []}`)},
+		{[]byte(`{"a": [-1, 18446744073709551615]}`)},
+	}
+	for _, samples := range invalidSamples {
+		_, err = InferSchema(samples...)
+		if !errors.As(err, &expectErr) {
+			t.Errorf("InferSchema %s should fail, err: %v", samples, err)
+		}
+	}
+	_, err = InferSchema([]byte(`{"a": {"b": [1, "x"]}}`))
+	if !errors.As(err, &expectErr) || expectErr.Path != "a.b[1]" || expectErr.Sample != 0 {
+		t.Errorf("InferSchema conflicting types, expect error at a.b[1], err: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package schemafile loads schema definition files for the command line tools of jsonpack.
+package schemafile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/arloliu/jsonpack"
+)
+
+// Load loads schema definition from fileName, the file is in YAML format if the file extension
+// is ".yaml" or ".yml", otherwise it's in JSON format.
+func Load(fileName string) (*jsonpack.SchemaDef, error) {
+	data, err := ioutil.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == ".yaml" || ext == ".yml" {
+		return jsonpack.LoadSchemaYAML(data)
+	}
+
+	schDef := jsonpack.SchemaDef{}
+	err = json.Unmarshal(data, &schDef)
+	if err != nil {
+		return nil, err
+	}
+	return &schDef, nil
+}
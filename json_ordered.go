@@ -0,0 +1,90 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// orderedObject represents a JSON object which keeps the order of keys in document.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// get returns the value of key, and reports whether the key exists.
+func (o *orderedObject) get(key string) (interface{}, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// parseOrderedJSON parses JSON document into values which object is represented as *orderedObject,
+// array as []interface{}, number as json.Number, and the other values are the same as json.Unmarshal.
+func parseOrderedJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := parseOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.Errorf("invalid data after top-level value at offset %d", dec.InputOffset())
+	}
+	return v, nil
+}
+
+func parseOrderedValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := &orderedObject{values: make(map[string]interface{})}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			val, err := parseOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, exist := obj.values[key]; !exist {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = val
+		}
+		// consume '}'
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case '[':
+		arr := make([]interface{}, 0)
+		for dec.More() {
+			val, err := parseOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+		// consume ']'
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return nil, errors.Errorf("unexpected delimiter '%v' at offset %d", delim, dec.InputOffset())
+}
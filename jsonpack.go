@@ -1,6 +1,13 @@
 package jsonpack
 
-import "github.com/pkg/errors"
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+
+	"github.com/modern-go/reflect2"
+	"github.com/pkg/errors"
+)
 
 // JSONPack provides top-level operations for schema.
 type JSONPack struct {
@@ -17,6 +24,9 @@ func NewJSONPack() *JSONPack {
 /*
 AddSchema compiles schema definition and stores compiled result in internal schema manager.
 
+The new schema will be version 1 if the schema doesn't exist, or replaces the latest version
+of existing schema, use AddSchemaVersion method to add schema with specific version.
+
 It's a variadic function which accepts two input argument forms in the following.
 
 AddSchema(schemaName string, v interface{})
@@ -100,6 +110,96 @@ func (p *JSONPack) AddSchema(schemaName string, v ...interface{}) (*Schema, erro
 	return sch, nil
 }
 
+/*
+AddSchemaVersion compiles schema definition and stores compiled result as the specified
+version of schema in internal schema manager, the existing schema with the same version
+will be replaced.
+
+The arguments of v are the same as AddSchema method.
+
+Multiple versions of a schema can co-exist in schema manager, it's useful when different
+versions of encoded data are in use at the same time, likes rolling deployment.
+The schema with the highest version number is the latest version, and it's the one used by
+methods which don't accept version argument likes Encode, Decode and GetSchema.
+
+Example of adding two versions of schema:
+	jsonPack := jsonpack.NewJSONPack()
+	_, err := jsonPack.AddSchemaVersion("Info", 1, InfoV1{})
+	_, err = jsonPack.AddSchemaVersion("Info", 2, InfoV2{})
+
+	// encodes data with version 1 of Info schema, the version number is stored in encoded data
+	encoded, err := jsonPack.EncodeVersion("Info", 1, &InfoV1{Name: "example name"})
+
+	// decodes data with the version of Info schema specified in encoded data
+	infoMap := make(map[string]interface{})
+	version, err := jsonPack.DecodeVersion("Info", encoded, &infoMap)
+*/
+func (p *JSONPack) AddSchemaVersion(schemaName string, version uint32, v ...interface{}) (*Schema, error) {
+	sch, err := p.schemaManager.addVersion(schemaName, version, v...)
+	if err != nil {
+		return nil, errors.WithStack(&CompileError{schemaName, err})
+	}
+	return sch, nil
+}
+
+// GetSchemaVersion returns the specified version of schema instance by schemaName,
+// returns nil if schema or version not found.
+func (p *JSONPack) GetSchemaVersion(schemaName string, version uint32) *Schema {
+	return p.schemaManager.getVersion(schemaName, version)
+}
+
+// GetSchemaVersions returns all version numbers of schema in ascending order,
+// it returns *SchemaNonExistError error if schema not found.
+func (p *JSONPack) GetSchemaVersions(schemaName string) ([]uint32, error) {
+	versions := p.schemaManager.listVersions(schemaName)
+	if versions == nil {
+		return nil, errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+	return versions, nil
+}
+
+// LatestVersion returns the highest version number of schema,
+// it returns *SchemaNonExistError error if schema not found.
+func (p *JSONPack) LatestVersion(schemaName string) (uint32, error) {
+	version, ok := p.schemaManager.latestVersion(schemaName)
+	if !ok {
+		return 0, errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+	return version, nil
+}
+
+// EncodeVersion encodes v with the specified version of schema, and stores the version number
+// as a varuint header in front of the encoded data, the encoded data can then be decoded
+// by DecodeVersion method.
+//
+// It returns *SchemaVersionNonExistError error if schema or version not found.
+func (p *JSONPack) EncodeVersion(schemaName string, version uint32, v interface{}) ([]byte, error) {
+	schema := p.schemaManager.getVersion(schemaName, version)
+	if schema == nil {
+		return nil, errors.WithStack(&SchemaVersionNonExistError{schemaName, version})
+	}
+	return schema.encodeWithHeader(uint64(version), v)
+}
+
+// DecodeVersion reads the version number from header of encoded data which encoded by
+// EncodeVersion method, decodes data with that version of schema and stores the result
+// in the value pointed to v.
+//
+// It returns the version number of schema which used to decode,
+// and returns *SchemaVersionNonExistError error if schema or version not found.
+func (p *JSONPack) DecodeVersion(schemaName string, data []byte, v interface{}) (uint32, error) {
+	version, n := binary.Uvarint(data)
+	if n <= 0 || version > math.MaxUint32 {
+		return 0, errors.WithStack(&DecodeError{schemaName, errors.New("invalid version header")})
+	}
+
+	schema := p.schemaManager.getVersion(schemaName, uint32(version))
+	if schema == nil {
+		return 0, errors.WithStack(&SchemaVersionNonExistError{schemaName, uint32(version)})
+	}
+	return uint32(version), schema.decode(data[n:], v, true, false)
+}
+
 // Encode is a wrapper of Schema.Encode,
 // it returns *SchemaNonExistError error if schema not found.
 func (p *JSONPack) Encode(schemaName string, v interface{}) ([]byte, error) {
@@ -127,7 +227,125 @@ func (p *JSONPack) Decode(schemaName string, data []byte, v interface{}) error {
 	if schema == nil {
 		return errors.WithStack(&SchemaNonExistError{schemaName})
 	}
-	return schema.decode(data, v, true)
+	return schema.decode(data, v, true, false)
+}
+
+// DecodeProjection is a wrapper of Schema.DecodeProjection,
+// it returns *SchemaNonExistError error if schema not found.
+func (p *JSONPack) DecodeProjection(schemaName string, data []byte, v interface{}) error {
+	schema := p.schemaManager.get(schemaName)
+	if schema == nil {
+		return errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+	return schema.DecodeProjection(data, v)
+}
+
+/*
+RegisterEnvelope assigns an envelope id to schema for EncodeEnvelope and DecodeEnvelope methods.
+
+Envelope encoding is useful when multiplexing many kinds of messages on one channel,
+the envelope id is stored as a varuint header in front of the encoded data, so the decoder
+knows which schema to use. Small id number takes less space, the id number which less than
+128 takes only one byte.
+
+The optional v argument is a value of the type which DecodeEnvelope method decodes into,
+DecodeEnvelope decodes into map[string]interface{} (or []interface{} for array schema)
+if v is not specified.
+
+It returns *SchemaNonExistError error if schema not found, and returns error if the id
+has been registered by another schema. Registering a schema again replaces its previous id.
+
+Example:
+	jsonPack := jsonpack.NewJSONPack()
+	jsonPack.AddSchema("Info", Info{})
+	jsonPack.AddSchema("Status", Status{})
+	jsonPack.RegisterEnvelope("Info", 1, Info{})
+	jsonPack.RegisterEnvelope("Status", 2)
+
+	encoded, err := jsonPack.EncodeEnvelope("Info", &Info{Name: "example name"})
+
+	// name is "Info", and v is *Info type
+	name, v, err := jsonPack.DecodeEnvelope(encoded)
+*/
+func (p *JSONPack) RegisterEnvelope(schemaName string, id uint64, v ...interface{}) error {
+	if p.schemaManager.get(schemaName) == nil {
+		return errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+
+	var typ reflect2.Type
+	if len(v) > 0 && v[0] != nil {
+		typ = reflect2.TypeOf(v[0])
+		if typ.Kind() == reflect.Ptr {
+			typ = toPtrElemType(typ)
+		}
+	}
+	return p.schemaManager.registerEnvelope(schemaName, id, typ)
+}
+
+// EncodeEnvelope encodes v with schema, and stores the envelope id of schema as a varuint header
+// in front of the encoded data, the encoded data can then be decoded by DecodeEnvelope method.
+//
+// It returns *EnvelopeNonExistError error if schema hasn't registered by RegisterEnvelope method,
+// and returns *SchemaNonExistError error if schema not found.
+func (p *JSONPack) EncodeEnvelope(schemaName string, v interface{}) ([]byte, error) {
+	info := p.schemaManager.getEnvelopeByName(schemaName)
+	if info == nil {
+		return nil, errors.WithStack(&EnvelopeNonExistError{Name: schemaName})
+	}
+	schema := p.schemaManager.get(schemaName)
+	if schema == nil {
+		return nil, errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+	return schema.encodeWithHeader(info.id, v)
+}
+
+// DecodeEnvelope reads envelope id from header of encoded data which encoded by EncodeEnvelope method,
+// and decodes data with the schema registered with the envelope id.
+//
+// It returns the schema name and decoded value, the decoded value is a pointer to new value
+// of the type registered by RegisterEnvelope method, or a map[string]interface{} (or []interface{}
+// for array schema) if no type registered.
+//
+// It returns *EnvelopeNonExistError error if envelope id not registered.
+func (p *JSONPack) DecodeEnvelope(data []byte) (string, interface{}, error) {
+	id, n := binary.Uvarint(data)
+	if n <= 0 {
+		return "", nil, errors.WithStack(&DecodeError{"", errors.New("invalid envelope header")})
+	}
+
+	info := p.schemaManager.getEnvelopeByID(id)
+	if info == nil {
+		return "", nil, errors.WithStack(&EnvelopeNonExistError{ID: id})
+	}
+	schema := p.schemaManager.get(info.name)
+	if schema == nil {
+		return info.name, nil, errors.WithStack(&SchemaNonExistError{info.name})
+	}
+
+	if info.typ != nil {
+		v := info.typ.New()
+		err := schema.decode(data[n:], v, true, false)
+		if err != nil {
+			return info.name, nil, err
+		}
+		return info.name, v, nil
+	}
+
+	if schema.rootOp.handlerType == objectOpType {
+		v := make(map[string]interface{})
+		err := schema.decode(data[n:], &v, true, false)
+		if err != nil {
+			return info.name, nil, err
+		}
+		return info.name, v, nil
+	}
+
+	var v []interface{}
+	err := schema.decode(data[n:], &v, true, false)
+	if err != nil {
+		return info.name, nil, err
+	}
+	return info.name, v, nil
 }
 
 // Marshal is an alias to Encode function, provides familiar interface of standard json package.
@@ -140,7 +358,7 @@ func (p *JSONPack) Unmarshal(schemaName string, data []byte, v interface{}) erro
 	return p.Decode(schemaName, data, v)
 }
 
-// GetSchema returns a schema instance by schemaName, returns nil if schema not found.
+// GetSchema returns the latest version of schema instance by schemaName, returns nil if schema not found.
 func (p *JSONPack) GetSchema(schemaName string) *Schema {
 	return p.schemaManager.get(schemaName)
 }
@@ -184,12 +402,18 @@ func (p *JSONPack) GetAllSchemaDefTexts() map[string][]byte {
 	return p.schemaManager.getAllSchemaDefTexts()
 }
 
-// RemoveSchema removes schema by schemaName, it returns *SchemaNonExistError error
-// if schema not found.
+// RemoveSchema removes all versions of schema by schemaName and releases its envelope id,
+// it returns *SchemaNonExistError error if schema not found.
 func (p *JSONPack) RemoveSchema(schemaName string) error {
 	return p.schemaManager.remove(schemaName)
 }
 
+// RemoveSchemaVersion removes the specified version of schema, the schema will be removed and its
+// envelope id will be released if there is no version left. It returns *SchemaVersionNonExistError error if schema or version not found.
+func (p *JSONPack) RemoveSchemaVersion(schemaName string, version uint32) error {
+	return p.schemaManager.removeVersion(schemaName, version)
+}
+
 // Reset removes all schema instances
 func (p *JSONPack) Reset() {
 	p.schemaManager.reset()
@@ -5,6 +5,8 @@ import (
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 type s1 struct {
@@ -179,3 +181,159 @@ func TestGetAll(t *testing.T) {
 	}
 
 }
+
+type infoV1 struct {
+	Name string `json:"name"`
+}
+
+type infoV2 struct {
+	Name string `json:"name"`
+	Area uint32 `json:"area"`
+}
+
+func TestSchemaVersion(t *testing.T) {
+	var jsonPacker *JSONPack = NewJSONPack()
+	var err error
+
+	_, err = jsonPacker.AddSchemaVersion("info", 1, infoV1{})
+	if err != nil {
+		t.Errorf("AddSchemaVersion(info, 1) fail, err: %v", err)
+	}
+	_, err = jsonPacker.AddSchemaVersion("info", 2, infoV2{})
+	if err != nil {
+		t.Errorf("AddSchemaVersion(info, 2) fail, err: %v", err)
+	}
+
+	latest, err := jsonPacker.LatestVersion("info")
+	if err != nil || latest != 2 {
+		t.Errorf("LatestVersion(info) should be 2, got %d, err: %v", latest, err)
+	}
+	if jsonPacker.GetSchema("info") != jsonPacker.GetSchemaVersion("info", 2) {
+		t.Error("GetSchema(info) should return the latest version")
+	}
+	versions, err := jsonPacker.GetSchemaVersions("info")
+	if err != nil || !reflect.DeepEqual(versions, []uint32{1, 2}) {
+		t.Errorf("GetSchemaVersions(info) fail, got %v, err: %v", versions, err)
+	}
+
+	encV1, err := jsonPacker.EncodeVersion("info", 1, &infoV1{Name: "v1"})
+	if err != nil {
+		t.Errorf("EncodeVersion(info, 1) fail, err: %v", err)
+	}
+	encV2, err := jsonPacker.EncodeVersion("info", 2, &infoV2{Name: "v2", Area: 888})
+	if err != nil {
+		t.Errorf("EncodeVersion(info, 2) fail, err: %v", err)
+	}
+
+	decMap := make(map[string]interface{})
+	ver, err := jsonPacker.DecodeVersion("info", encV1, &decMap)
+	if err != nil || ver != 1 || !compareMap(decMap, map[string]interface{}{"name": "v1"}) {
+		t.Errorf("DecodeVersion v1 fail, version: %d, data: %v, err: %v", ver, decMap, err)
+	}
+
+	decStruct := infoV2{}
+	ver, err = jsonPacker.DecodeVersion("info", encV2, &decStruct)
+	if err != nil || ver != 2 || decStruct != (infoV2{Name: "v2", Area: 888}) {
+		t.Errorf("DecodeVersion v2 fail, version: %d, data: %v, err: %v", ver, decStruct, err)
+	}
+
+	// AddSchema replaces the latest version
+	sch, err := jsonPacker.AddSchema("info", infoV2{})
+	if err != nil || sch.Version != 2 {
+		t.Errorf("AddSchema(info) should replace version 2, err: %v", err)
+	}
+
+	err = jsonPacker.RemoveSchemaVersion("info", 2)
+	if err != nil {
+		t.Errorf("RemoveSchemaVersion(info, 2) fail, err: %v", err)
+	}
+	if latest, _ = jsonPacker.LatestVersion("info"); latest != 1 {
+		t.Errorf("LatestVersion(info) should be 1 after removing version 2, got %d", latest)
+	}
+
+	_, err = jsonPacker.DecodeVersion("info", encV2, &decMap)
+	var expectErr *SchemaVersionNonExistError
+	if !errors.As(err, &expectErr) {
+		t.Errorf("DecodeVersion should return SchemaVersionNonExistError, err: %v", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	var jsonPacker *JSONPack = NewJSONPack()
+	var err error
+
+	_, err = jsonPacker.AddSchema("s1", s1{})
+	if err != nil {
+		t.Errorf("AddSchema(s1) fail, err: %v", err)
+	}
+	_, err = jsonPacker.AddSchema("s2", s2{})
+	if err != nil {
+		t.Errorf("AddSchema(s2) fail, err: %v", err)
+	}
+
+	if err = jsonPacker.RegisterEnvelope("s1", 1, s1{}); err != nil {
+		t.Errorf("RegisterEnvelope(s1) fail, err: %v", err)
+	}
+	if err = jsonPacker.RegisterEnvelope("s2", 300); err != nil {
+		t.Errorf("RegisterEnvelope(s2) fail, err: %v", err)
+	}
+	if err = jsonPacker.RegisterEnvelope("s2", 1); err == nil {
+		t.Error("RegisterEnvelope(s2) should fail with duplicated id")
+	}
+	if err = jsonPacker.RegisterEnvelope("nonexist", 2); err == nil {
+		t.Error("RegisterEnvelope(nonexist) should fail")
+	}
+
+	enc1, err := jsonPacker.EncodeEnvelope("s1", &s1{ID: "1", Msg: "msg1"})
+	if err != nil {
+		t.Errorf("EncodeEnvelope(s1) fail, err: %v", err)
+	}
+	if enc1[0] != 1 {
+		t.Errorf("envelope header of s1 should be 1, got %d", enc1[0])
+	}
+	enc2, err := jsonPacker.EncodeEnvelope("s2", map[string]interface{}{"id": "2", "msg": "msg2"})
+	if err != nil {
+		t.Errorf("EncodeEnvelope(s2) fail, err: %v", err)
+	}
+
+	name, v, err := jsonPacker.DecodeEnvelope(enc1)
+	if err != nil || name != "s1" || !reflect.DeepEqual(v, &s1{ID: "1", Msg: "msg1"}) {
+		t.Errorf("DecodeEnvelope(s1) fail, name: %s, v: %+v, err: %v", name, v, err)
+	}
+
+	name, v, err = jsonPacker.DecodeEnvelope(enc2)
+	if err != nil || name != "s2" || !compareMap(v, map[string]interface{}{"id": "2", "msg": "msg2"}) {
+		t.Errorf("DecodeEnvelope(s2) fail, name: %s, v: %+v, err: %v", name, v, err)
+	}
+
+	var expectErr *EnvelopeNonExistError
+	_, _, err = jsonPacker.DecodeEnvelope([]byte{2, 0, 0})
+	if !errors.As(err, &expectErr) {
+		t.Errorf("DecodeEnvelope should return EnvelopeNonExistError, err: %v", err)
+	}
+	_, err = jsonPacker.EncodeEnvelope("nonexist", map[string]interface{}{})
+	if !errors.As(err, &expectErr) {
+		t.Errorf("EncodeEnvelope should return EnvelopeNonExistError, err: %v", err)
+	}
+
+	// removed schemas release their envelope ids
+	if err = jsonPacker.RemoveSchema("s1"); err != nil {
+		t.Errorf("RemoveSchema(s1) fail, err: %v", err)
+	}
+	if err = jsonPacker.RemoveSchemaVersion("s2", 1); err != nil {
+		t.Errorf("RemoveSchemaVersion(s2) fail, err: %v", err)
+	}
+	for _, enc := range [][]byte{enc1, enc2} {
+		_, _, err = jsonPacker.DecodeEnvelope(enc)
+		if !errors.As(err, &expectErr) {
+			t.Errorf("DecodeEnvelope of removed schema should return EnvelopeNonExistError, err: %v", err)
+		}
+	}
+	_, err = jsonPacker.AddSchema("s3", s1{})
+	if err != nil {
+		t.Errorf("AddSchema(s3) fail, err: %v", err)
+	}
+	if err = jsonPacker.RegisterEnvelope("s3", 1); err != nil {
+		t.Errorf("RegisterEnvelope(s3) with released id fail, err: %v", err)
+	}
+}
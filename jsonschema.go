@@ -0,0 +1,711 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PropertyOrder represents the rule of ordering properties when converting schema of other formats
+// to schema definition.
+type PropertyOrder int
+
+const (
+	// DocumentOrder orders properties by the order of appearance in schema document.
+	DocumentOrder PropertyOrder = iota
+	// SortedOrder orders properties by name in lexical order.
+	SortedOrder
+)
+
+// JSONSchemaOptions represents the options of FromJSONSchema function.
+type JSONSchemaOptions struct {
+	// rule of ordering properties, the default is DocumentOrder
+	Order PropertyOrder
+	// use big-endian number types instead of little-endian types
+	BigEndian bool
+}
+
+// the keywords of JSON Schema that can't be represented by schema definition
+var jsonSchemaUnsupportedKeywords = []string{
+	"allOf", "not", "if", "then", "else",
+	"patternProperties", "dependentSchemas", "prefixItems",
+	"$dynamicRef", "$recursiveRef", "unevaluatedProperties", "unevaluatedItems",
+}
+
+// jsonSchemaTypeKeyword is the extension keyword which specifies the jsonpack type of property explicitly,
+// likes {"type": "integer", "x-jsonpack-type": "uint16be"}.
+const jsonSchemaTypeKeyword = "x-jsonpack-type"
+
+/*
+FromJSONSchema converts JSON Schema (draft 2020-12) document doc to schema definition.
+
+The types of JSON Schema are converted as:
+
+* "object" to object type, the properties are ordered by opts.Order, and the properties which are
+not listed in "required" or nullable are marked as optional, the names in "required" which aren't
+defined in "properties" are ignored.
+
+* "array" to array type, it requires "items" keyword.
+
+* "string" to string type, the "format" keyword is ignored.
+
+* "boolean" to boolean type.
+
+* "integer" to the integer type of "format" keyword if it's one of "int8", "int16", "int32", "int64",
+"uint8", "uint16", "uint32" and "uint64". Otherwise, the narrowest integer type which fits the range of
+"minimum", "maximum", "exclusiveMinimum" and "exclusiveMaximum" keywords, the range is int64 if
+bounds are not specified.
+
+* "number" to float32 type if "format" keyword is "float", or float64 type otherwise.
+
+* "enum" and "const" to the type of values, the enumeration of integers uses the narrowest integer type
+which fits all values.
+
+The local references likes "#/$defs/Name" are resolved, and the "x-jsonpack-type" extension keyword
+specifies jsonpack type of property explicitly, likes {"type": "integer", "x-jsonpack-type": "uint16be"}.
+
+The nullable type likes {"type": ["string", "null"]} or {"anyOf": [{"type": "string"}, {"type": "null"}]}
+is converted to the non-null type and marked as optional.
+
+It returns *ConvertError error with the JSON pointer of schema location if doc has constructs that
+can't be represented by schema definition, likes "allOf", unions of multiple types, recursive references,
+remote references, or objects with "additionalProperties" schema.
+
+Example:
+	schDef, err := jsonpack.FromJSONSchema(doc, jsonpack.JSONSchemaOptions{Order: jsonpack.SortedOrder})
+	sch, err := jsonPack.AddSchema("Order", *schDef)
+*/
+func FromJSONSchema(doc []byte, opts JSONSchemaOptions) (*SchemaDef, error) {
+	root, err := parseOrderedJSON(doc)
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+
+	c := jsonSchemaConverter{root: root, opts: opts, resolving: make(map[string]bool)}
+	schDef, _, err := c.convert(root, "#")
+	if err != nil {
+		return nil, err
+	}
+	if schDef.Type != "object" && schDef.Type != "array" {
+		return nil, c.errorf("#", "type of root schema needs to be object or array, got '%s'", schDef.Type)
+	}
+	return schDef, nil
+}
+
+// jsonSchemaConverter converts JSON Schema document to schema definition.
+type jsonSchemaConverter struct {
+	root interface{}
+	opts JSONSchemaOptions
+	// references which are being resolved, for detecting recursive references
+	resolving map[string]bool
+}
+
+func (c *jsonSchemaConverter) errorf(path string, format string, args ...interface{}) error {
+	return errors.WithStack(&ConvertError{path, errors.Errorf(format, args...)})
+}
+
+func (c *jsonSchemaConverter) endianSuffix() string {
+	if c.opts.BigEndian {
+		return "be"
+	}
+	return "le"
+}
+
+// convert converts schema v at path to schema definition, and reports whether the schema is nullable.
+func (c *jsonSchemaConverter) convert(v interface{}, path string) (*SchemaDef, bool, error) {
+	schema, ok := v.(*orderedObject)
+	if !ok {
+		if _, isBool := v.(bool); isBool {
+			return nil, false, c.errorf(path, "boolean schema is not supported")
+		}
+		return nil, false, c.errorf(path, "schema needs to be an object")
+	}
+
+	for _, keyword := range jsonSchemaUnsupportedKeywords {
+		if _, exist := schema.get(keyword); exist {
+			return nil, false, c.errorf(path+"/"+keyword, "keyword '%s' is not supported", keyword)
+		}
+	}
+
+	if ref, exist := schema.get("$ref"); exist {
+		return c.resolveRef(ref, path+"/$ref")
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if members, exist := schema.get(keyword); exist {
+			return c.convertUnion(members, path+"/"+keyword)
+		}
+	}
+
+	typ, nullable, err := c.schemaType(schema, path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if explicitType, exist := schema.get(jsonSchemaTypeKeyword); exist && typ != "object" && typ != "array" {
+		name, _ := explicitType.(string)
+		if _, ok := builtinOpHandlerTypes[strings.ToLower(name)]; !ok {
+			return nil, false, c.errorf(path+"/"+jsonSchemaTypeKeyword, "unknown type '%v'", explicitType)
+		}
+		return &SchemaDef{Type: name}, nullable, nil
+	}
+
+	var schDef *SchemaDef
+	switch typ {
+	case "object":
+		schDef, err = c.convertObject(schema, path)
+	case "array":
+		schDef, err = c.convertArray(schema, path)
+	case "string":
+		schDef = &SchemaDef{Type: "string"}
+	case "boolean":
+		schDef = &SchemaDef{Type: "boolean"}
+	case "integer":
+		schDef, err = c.convertInteger(schema, path)
+	case "number":
+		schDef = &SchemaDef{Type: "float64" + c.endianSuffix()}
+		if format, _ := schema.values["format"].(string); format == "float" || format == "float32" {
+			schDef.Type = "float32" + c.endianSuffix()
+		}
+	default:
+		err = c.errorf(path, "type '%s' is not supported", typ)
+	}
+	return schDef, nullable, err
+}
+
+// schemaType returns the JSON type of schema, and reports whether the schema is nullable.
+func (c *jsonSchemaConverter) schemaType(schema *orderedObject, path string) (string, bool, error) {
+	typeVal, exist := schema.get("type")
+	if !exist {
+		if _, ok := schema.get("enum"); ok {
+			return c.enumType(schema, path)
+		}
+		if _, ok := schema.get("const"); ok {
+			return c.enumType(schema, path)
+		}
+		if _, ok := schema.get("properties"); ok {
+			return "object", false, nil
+		}
+		if _, ok := schema.get("items"); ok {
+			return "array", false, nil
+		}
+		return "", false, c.errorf(path, "type is not specified")
+	}
+
+	var types []interface{}
+	switch typeVal := typeVal.(type) {
+	case string:
+		types = []interface{}{typeVal}
+	case []interface{}:
+		types = typeVal
+	default:
+		return "", false, c.errorf(path+"/type", "type needs to be a string or an array of strings")
+	}
+
+	typ := ""
+	nullable := false
+	for _, t := range types {
+		name, ok := t.(string)
+		switch {
+		case !ok:
+			return "", false, c.errorf(path+"/type", "type needs to be a string or an array of strings")
+		case name == "null":
+			nullable = true
+		case typ != "":
+			return "", false, c.errorf(path+"/type", "union of types '%s' and '%s' is not supported", typ, name)
+		default:
+			typ = name
+		}
+	}
+	if typ == "" {
+		return "", false, c.errorf(path+"/type", "null type is not supported")
+	}
+
+	// the enumeration of integers decides the integer type by its values
+	if typ == "integer" || typ == "number" {
+		if _, ok := schema.get("enum"); ok {
+			enumTyp, enumNullable, err := c.enumType(schema, path)
+			if err != nil {
+				return "", false, err
+			}
+			if enumTyp != "integer" && enumTyp != typ {
+				return "", false, c.errorf(path+"/enum", "enumeration of %s doesn't match type '%s'", enumTyp, typ)
+			}
+			typ = enumTyp
+			nullable = nullable || enumNullable
+		}
+	}
+	return typ, nullable, nil
+}
+
+// enumType returns the JSON type of values of "enum" or "const" keyword, and reports whether null is
+// one of the values.
+func (c *jsonSchemaConverter) enumType(schema *orderedObject, path string) (string, bool, error) {
+	var values []interface{}
+	if enum, ok := schema.get("enum"); ok {
+		path += "/enum"
+		if values, ok = enum.([]interface{}); !ok || len(values) == 0 {
+			return "", false, c.errorf(path, "enum needs to be a non-empty array")
+		}
+	} else {
+		path += "/const"
+		values = []interface{}{schema.values["const"]}
+	}
+
+	typ := ""
+	nullable := false
+	for _, v := range values {
+		var valTyp string
+		switch v := v.(type) {
+		case nil:
+			nullable = true
+			continue
+		case string:
+			valTyp = "string"
+		case bool:
+			valTyp = "boolean"
+		case json.Number:
+			valTyp = "integer"
+			if _, ok := new(big.Int).SetString(v.String(), 10); !ok {
+				valTyp = "number"
+			}
+		default:
+			return "", false, c.errorf(path, "enumeration of objects or arrays is not supported")
+		}
+
+		switch {
+		case typ == "" || typ == valTyp:
+			typ = valTyp
+		case typ == "integer" && valTyp == "number", typ == "number" && valTyp == "integer":
+			typ = "number"
+		default:
+			return "", false, c.errorf(path, "enumeration of types '%s' and '%s' is not supported", typ, valTyp)
+		}
+	}
+	if typ == "" {
+		return "", false, c.errorf(path, "enumeration of null is not supported")
+	}
+	return typ, nullable, nil
+}
+
+// convertUnion converts "anyOf" or "oneOf" members which have exactly one non-null member.
+func (c *jsonSchemaConverter) convertUnion(v interface{}, path string) (*SchemaDef, bool, error) {
+	members, ok := v.([]interface{})
+	if !ok || len(members) == 0 {
+		return nil, false, c.errorf(path, "union needs to be a non-empty array")
+	}
+
+	var member interface{}
+	memberIdx := -1
+	nullable := false
+	for i, m := range members {
+		if obj, ok := m.(*orderedObject); ok && len(obj.keys) == 1 {
+			if t, _ := obj.values["type"].(string); t == "null" {
+				nullable = true
+				continue
+			}
+		}
+		if member != nil {
+			return nil, false, c.errorf(path, "union of multiple types is not supported")
+		}
+		member, memberIdx = m, i
+	}
+	if member == nil {
+		return nil, false, c.errorf(path, "null type is not supported")
+	}
+
+	schDef, memberNullable, err := c.convert(member, path+"/"+strconv.Itoa(memberIdx))
+	return schDef, nullable || memberNullable, err
+}
+
+// resolveRef converts the schema referenced by local reference ref.
+func (c *jsonSchemaConverter) resolveRef(v interface{}, path string) (*SchemaDef, bool, error) {
+	ref, ok := v.(string)
+	if !ok {
+		return nil, false, c.errorf(path, "reference needs to be a string")
+	}
+	if !strings.HasPrefix(ref, "#") || (len(ref) > 1 && !strings.HasPrefix(ref, "#/")) {
+		return nil, false, c.errorf(path, "reference '%s' is not supported, only local JSON pointer is supported", ref)
+	}
+	if c.resolving[ref] {
+		return nil, false, c.errorf(path, "recursive reference '%s' is not supported", ref)
+	}
+
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, false, c.errorf(path, "invalid reference '%s': %v", ref, err)
+	}
+	target := c.root
+	if pointer != "" {
+		for _, token := range strings.Split(pointer[1:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			switch node := target.(type) {
+			case *orderedObject:
+				target, ok = node.get(token)
+			case []interface{}:
+				var idx int
+				idx, err = strconv.Atoi(token)
+				ok = err == nil && idx >= 0 && idx < len(node)
+				if ok {
+					target = node[idx]
+				}
+			default:
+				ok = false
+			}
+			if !ok {
+				return nil, false, c.errorf(path, "reference '%s' doesn't exist", ref)
+			}
+		}
+	}
+
+	c.resolving[ref] = true
+	defer delete(c.resolving, ref)
+	return c.convert(target, ref)
+}
+
+func (c *jsonSchemaConverter) convertObject(schema *orderedObject, path string) (*SchemaDef, error) {
+	if additional, exist := schema.get("additionalProperties"); exist {
+		if _, isBool := additional.(bool); !isBool {
+			return nil, c.errorf(path+"/additionalProperties", "schema of additional properties is not supported")
+		}
+	}
+
+	props := &orderedObject{values: make(map[string]interface{})}
+	if v, exist := schema.get("properties"); exist {
+		var ok bool
+		if props, ok = v.(*orderedObject); !ok {
+			return nil, c.errorf(path+"/properties", "properties needs to be an object")
+		}
+	}
+
+	required := make(map[string]bool)
+	if v, exist := schema.get("required"); exist {
+		names, ok := v.([]interface{})
+		if !ok {
+			return nil, c.errorf(path+"/required", "required needs to be an array of strings")
+		}
+		for _, n := range names {
+			name, ok := n.(string)
+			if !ok {
+				return nil, c.errorf(path+"/required", "required needs to be an array of strings")
+			}
+			// the required properties which aren't defined in properties are ignored, they can't be encoded
+			required[name] = true
+		}
+	}
+
+	order := append(make([]string, 0, len(props.keys)), props.keys...)
+	if c.opts.Order == SortedOrder {
+		sort.Strings(order)
+	}
+
+	schDef := &SchemaDef{
+		Type:       "object",
+		Properties: make(map[string]*SchemaDef),
+		Order:      order,
+	}
+	for _, name := range order {
+		propDef, nullable, err := c.convert(props.values[name], path+"/properties/"+escapeJSONPointer(name))
+		if err != nil {
+			return nil, err
+		}
+		propDef.Optional = nullable || !required[name]
+		schDef.Properties[name] = propDef
+	}
+	return schDef, nil
+}
+
+func (c *jsonSchemaConverter) convertArray(schema *orderedObject, path string) (*SchemaDef, error) {
+	items, exist := schema.get("items")
+	if !exist {
+		return nil, c.errorf(path, "items of array is not specified")
+	}
+	itemDef, _, err := c.convert(items, path+"/items")
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaDef{Type: "array", Items: itemDef}, nil
+}
+
+// the integer formats which map to jsonpack types directly
+var jsonSchemaIntegerFormats = map[string]bool{
+	"int8": true, "int16": true, "int32": true, "int64": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true,
+}
+
+func (c *jsonSchemaConverter) convertInteger(schema *orderedObject, path string) (*SchemaDef, error) {
+	if format, _ := schema.values["format"].(string); jsonSchemaIntegerFormats[format] {
+		if format == "int8" || format == "uint8" {
+			return &SchemaDef{Type: format}, nil
+		}
+		return &SchemaDef{Type: format + c.endianSuffix()}, nil
+	}
+
+	var lo, hi *big.Int
+	if enum, ok := schema.get("enum"); ok {
+		// the enumeration contains integers and null only, it's checked by enumType method
+		for _, v := range enum.([]interface{}) {
+			if num, ok := v.(json.Number); ok {
+				n, _ := new(big.Int).SetString(num.String(), 10)
+				if lo == nil || n.Cmp(lo) < 0 {
+					lo = n
+				}
+				if hi == nil || n.Cmp(hi) > 0 {
+					hi = n
+				}
+			}
+		}
+	} else if n, ok := constInteger(schema); ok {
+		lo, hi = n, n
+	} else {
+		var err error
+		bounds := []struct {
+			keyword   string
+			lower     bool
+			exclusive bool
+		}{
+			{"minimum", true, false},
+			{"exclusiveMinimum", true, true},
+			{"maximum", false, false},
+			{"exclusiveMaximum", false, true},
+		}
+		for _, b := range bounds {
+			v, exist := schema.get(b.keyword)
+			if !exist {
+				continue
+			}
+			var bound *big.Int
+			bound, err = integerBound(v, b.lower, b.exclusive)
+			if err != nil {
+				return nil, c.errorf(path+"/"+b.keyword, "%v", err)
+			}
+			if b.lower && (lo == nil || bound.Cmp(lo) > 0) {
+				lo = bound
+			} else if !b.lower && (hi == nil || bound.Cmp(hi) < 0) {
+				hi = bound
+			}
+		}
+	}
+
+	if lo != nil && hi != nil && lo.Cmp(hi) > 0 {
+		return nil, c.errorf(path, "minimum %s is greater than maximum %s", lo, hi)
+	}
+
+	signed := lo == nil || lo.Sign() < 0
+	minInt := int64(math.MinInt64)
+	if lo != nil {
+		if !lo.IsInt64() {
+			return nil, c.errorf(path, "minimum %s exceeds the range of integer types", lo)
+		}
+		minInt = lo.Int64()
+	}
+	maxUint := uint64(math.MaxUint64)
+	if signed {
+		maxUint = math.MaxInt64
+	}
+	if hi != nil {
+		if hi.Sign() < 0 {
+			maxUint = 0
+		} else if hi.IsUint64() && (hi.Uint64() <= maxUint) {
+			maxUint = hi.Uint64()
+		} else {
+			return nil, c.errorf(path, "maximum %s exceeds the range of integer types", hi)
+		}
+	}
+
+	typ := narrowestIntType(minInt, maxUint, signed, c.opts.BigEndian)
+	if typ == "" {
+		return nil, c.errorf(path, "range from %d to %d exceeds the range of integer types", minInt, maxUint)
+	}
+	return &SchemaDef{Type: typ}, nil
+}
+
+// integerBound returns the integer bound of numeric keyword value v, the lower bound rounds up and
+// the upper bound rounds down.
+func integerBound(v interface{}, lower bool, exclusive bool) (*big.Int, error) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return nil, errors.New("bound needs to be a number")
+	}
+	r, ok := new(big.Rat).SetString(num.String())
+	if !ok {
+		return nil, errors.Errorf("invalid number %s", num)
+	}
+
+	// the denominator of big.Rat is always positive, Div rounds down
+	n := new(big.Int).Div(r.Num(), r.Denom())
+	isInt := r.IsInt()
+	switch {
+	case lower && !isInt:
+		n.Add(n, big.NewInt(1))
+	case lower && exclusive:
+		n.Add(n, big.NewInt(1))
+	case !lower && isInt && exclusive:
+		n.Sub(n, big.NewInt(1))
+	}
+	return n, nil
+}
+
+// constInteger returns the integer value of "const" keyword, and reports whether it exists.
+func constInteger(schema *orderedObject) (*big.Int, bool) {
+	num, ok := schema.values["const"].(json.Number)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).SetString(num.String(), 10)
+}
+
+// escapeJSONPointer escapes reference token of JSON pointer.
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+/*
+ToJSONSchema returns JSON Schema (draft 2020-12) document which validates the JSON form of documents
+accepted by schema, it's useful for publishing API documents and validators from jsonpack schema.
+
+The properties keep the order of schema definition, and the properties which are not optional are
+required. The integer types are converted to "integer" type with the range of type, likes "minimum": 0
+and "maximum": 65535 for "uint16le" type, and the float types are converted to "number" type with
+"float" or "double" format. The number types have "x-jsonpack-type" extension keyword, so the document
+can be converted back to the same schema definition by FromJSONSchema function.
+
+Example:
+	doc, err := sch.ToJSONSchema()
+	ioutil.WriteFile("info.schema.json", doc, 0644)
+*/
+func (s *Schema) ToJSONSchema() ([]byte, error) {
+	schDef, err := s.GetSchemaDef()
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`{"$schema":"https://json-schema.org/draft/2020-12/schema","title":`)
+	writeJSONString(&b, s.Name)
+	b.WriteByte(',')
+	err = writeJSONSchema(&b, schDef, "#", false)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte('}')
+
+	var out bytes.Buffer
+	err = json.Indent(&out, b.Bytes(), "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	return out.Bytes(), nil
+}
+
+// writeJSONSchema writes JSON Schema keywords of schema definition schDef to b, the keywords are
+// wrapped in braces if braces is true.
+func writeJSONSchema(b *bytes.Buffer, schDef *SchemaDef, path string, braces bool) error {
+	if schDef == nil {
+		return errors.WithStack(&ConvertError{path, errors.New("schema definition is nil")})
+	}
+	if braces {
+		b.WriteByte('{')
+	}
+
+	typ := strings.ToLower(schDef.Type)
+	switch typ {
+	case "object":
+		b.WriteString(`"type":"object","properties":{`)
+		required := make([]string, 0, len(schDef.Order))
+		for i, name := range schDef.Order {
+			prop, ok := schDef.Properties[name]
+			if !ok {
+				return errors.WithStack(&ConvertError{path, errors.Errorf("property '%s' in order doesn't exist", name)})
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, name)
+			b.WriteByte(':')
+			err := writeJSONSchema(b, prop, path+"/properties/"+escapeJSONPointer(name), true)
+			if err != nil {
+				return err
+			}
+			if !prop.Optional {
+				required = append(required, name)
+			}
+		}
+		b.WriteString(`},"required":[`)
+		for i, name := range required {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, name)
+		}
+		b.WriteByte(']')
+
+	case "array":
+		b.WriteString(`"type":"array","items":`)
+		err := writeJSONSchema(b, schDef.Items, path+"/items", true)
+		if err != nil {
+			return err
+		}
+
+	default:
+		opType, ok := builtinOpHandlerTypes[typ]
+		if !ok {
+			return errors.WithStack(&ConvertError{path, &UnknownTypeError{schDef.Type}})
+		}
+		name := opTypeName(opType)
+		switch {
+		case opType == stringOpType:
+			b.WriteString(`"type":"string"`)
+		case opType == booleanOpType:
+			b.WriteString(`"type":"boolean"`)
+		case isNumberType(opType) && opType >= float32LEOpType:
+			format := "double"
+			if opType == float32LEOpType || opType == float32BEOpType {
+				format = "float"
+			}
+			fmt.Fprintf(b, `"type":"number","format":"%s","%s":"%s"`, format, jsonSchemaTypeKeyword, name)
+		default:
+			min, max := integerTypeRange(opType)
+			fmt.Fprintf(b, `"type":"integer","minimum":%s,"maximum":%s,"%s":"%s"`, min, max, jsonSchemaTypeKeyword, name)
+		}
+	}
+
+	if braces {
+		b.WriteByte('}')
+	}
+	return nil
+}
+
+// integerTypeRange returns the minimum and maximum values of integer type in decimal representation.
+func integerTypeRange(opType opHandlerType) (string, string) {
+	switch opType {
+	case int8OpType:
+		return strconv.Itoa(math.MinInt8), strconv.Itoa(math.MaxInt8)
+	case int16LEOpType, int16BEOpType:
+		return strconv.Itoa(math.MinInt16), strconv.Itoa(math.MaxInt16)
+	case int32LEOpType, int32BEOpType:
+		return strconv.Itoa(math.MinInt32), strconv.Itoa(math.MaxInt32)
+	case int64LEOpType, int64BEOpType:
+		return strconv.FormatInt(math.MinInt64, 10), strconv.FormatInt(math.MaxInt64, 10)
+	case uint8OpType:
+		return "0", strconv.Itoa(math.MaxUint8)
+	case uint16LEOpType, uint16BEOpType:
+		return "0", strconv.Itoa(math.MaxUint16)
+	case uint32LEOpType, uint32BEOpType:
+		return "0", strconv.FormatUint(math.MaxUint32, 10)
+	}
+	return "0", strconv.FormatUint(math.MaxUint64, 10)
+}
+
+// writeJSONString writes s as JSON string to b.
+func writeJSONString(b *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
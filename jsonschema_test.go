@@ -0,0 +1,219 @@
+package jsonpack
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestFromJSONSchema(t *testing.T) {
+	doc := []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "format": "email"},
+			"area": {"type": "integer", "minimum": 0, "maximum": 1000},
+			"score": {"type": "integer", "exclusiveMinimum": -129, "exclusiveMaximum": 128},
+			"id": {"type": "integer", "format": "uint32"},
+			"count": {"type": "integer"},
+			"ratio": {"type": "number", "format": "float"},
+			"level": {"enum": [1, 2, 300]},
+			"status": {"enum": ["active", "inactive", null]},
+			"note": {"type": ["string", "null"]},
+			"flag": {"anyOf": [{"type": "boolean"}, {"type": "null"}]},
+			"tag": {"type": "integer", "x-jsonpack-type": "uint16be"},
+			"address": {"$ref": "#/$defs/Address"},
+			"history": {"type": "array", "items": {"$ref": "#/$defs/Address"}}
+		},
+		"required": ["name", "area", "score", "id", "count", "ratio", "level", "status", "note", "tag", "address", "history"],
+		"$defs": {
+			"Address": {
+				"type": "object",
+				"properties": {
+					"street": {"type": "string"},
+					"zip": {"type": "number"}
+				},
+				"required": ["street"]
+			}
+		}
+	}`)
+
+	schDef, err := FromJSONSchema(doc, JSONSchemaOptions{})
+	if err != nil {
+		t.Fatalf("FromJSONSchema fail, err: %+v", err)
+	}
+
+	addrDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"street": {Type: "string"},
+			"zip":    {Type: "float64le", Optional: true},
+		},
+		Order: []string{"street", "zip"},
+	}
+	expDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":    {Type: "string"},
+			"area":    {Type: "uint16le"},
+			"score":   {Type: "int8"},
+			"id":      {Type: "uint32le"},
+			"count":   {Type: "int64le"},
+			"ratio":   {Type: "float32le"},
+			"level":   {Type: "uint16le"},
+			"status":  {Type: "string", Optional: true},
+			"note":    {Type: "string", Optional: true},
+			"flag":    {Type: "boolean", Optional: true},
+			"tag":     {Type: "uint16be"},
+			"address": addrDef,
+			"history": {Type: "array", Items: addrDef},
+		},
+		Order: []string{"name", "area", "score", "id", "count", "ratio", "level", "status", "note", "flag", "tag", "address", "history"},
+	}
+	if !reflect.DeepEqual(schDef, expDef) {
+		t.Errorf("FromJSONSchema result: %+v, expect: %+v", schDef, expDef)
+	}
+
+	schDef, err = FromJSONSchema(doc, JSONSchemaOptions{Order: SortedOrder, BigEndian: true})
+	if err != nil {
+		t.Fatalf("FromJSONSchema fail, err: %+v", err)
+	}
+	expOrder := []string{"address", "area", "count", "flag", "history", "id", "level", "name", "note", "ratio", "score", "status", "tag"}
+	if !reflect.DeepEqual(schDef.Order, expOrder) {
+		t.Errorf("FromJSONSchema sorted order: %v, expect: %v", schDef.Order, expOrder)
+	}
+	if schDef.Properties["area"].Type != "uint16be" {
+		t.Errorf("FromJSONSchema big-endian type: %s, expect: uint16be", schDef.Properties["area"].Type)
+	}
+
+	if _, err = NewJSONPack().AddSchema("fromJSONSchema", *schDef); err != nil {
+		t.Errorf("AddSchema with converted schema definition fail, err: %+v", err)
+	}
+
+	// required property which isn't defined in properties is valid JSON Schema
+	schDef, err = FromJSONSchema([]byte(`{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a", "b"]}`), JSONSchemaOptions{})
+	if err != nil {
+		t.Errorf("FromJSONSchema with undefined required property fail, err: %+v", err)
+	} else if len(schDef.Order) != 1 || schDef.Properties["a"].Optional {
+		t.Errorf("FromJSONSchema with undefined required property result: %+v", schDef)
+	}
+
+	invalidDocs := map[string]string{
+		`{"type": "string"}`: "#",
+		`{"type": "object", "properties": {"a": {"allOf": [{"type": "string"}]}}}`:                                          "#/properties/a/allOf",
+		`{"type": "object", "properties": {"a": {"type": ["string", "integer"]}}}`:                                          "#/properties/a/type",
+		`{"type": "object", "properties": {"a": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}}`:                     "#/properties/a/oneOf",
+		`{"type": "object", "properties": {"a": {"$ref": "other.json#/a"}}}`:                                                "#/properties/a/$ref",
+		`{"type": "object", "properties": {"a": {"$ref": "#/$defs/none"}}}`:                                                 "#/properties/a/$ref",
+		`{"type": "object", "properties": {"a": {"type": "array"}}}`:                                                        "#/properties/a",
+		`{"type": "object", "additionalProperties": {"type": "string"}}`:                                                    "#/additionalProperties",
+		`{"type": "object", "properties": {"a~b": {"type": "integer", "minimum": 10, "maximum": 1}}}`:                       "#/properties/a~0b",
+		`{"$defs": {"Node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/Node"}}}}, "$ref": "#/$defs/Node"}`: "#/$defs/Node/properties/next/$ref",
+	}
+	for doc, path := range invalidDocs {
+		var convertErr *ConvertError
+		_, err = FromJSONSchema([]byte(doc), JSONSchemaOptions{})
+		if !errors.As(err, &convertErr) || convertErr.Path != path {
+			t.Errorf("FromJSONSchema %s should fail at '%s', err: %v", doc, path, err)
+		}
+	}
+}
+
+func TestToJSONSchema(t *testing.T) {
+	for _, name := range []string{"complex", "types", "sliceObject"} {
+		sch := jsonPack.GetSchema(name)
+		doc, err := sch.ToJSONSchema()
+		if err != nil {
+			t.Fatalf("ToJSONSchema of schema '%s' fail, err: %+v", name, err)
+		}
+
+		// the converted schema definition encodes the same data
+		schDef, err := FromJSONSchema(doc, JSONSchemaOptions{})
+		if err != nil {
+			t.Fatalf("FromJSONSchema of schema '%s' fail, err: %+v\n%s", name, err, doc)
+		}
+		newSch, err := NewJSONPack().AddSchema(name, *schDef)
+		if err != nil {
+			t.Fatalf("AddSchema with converted schema definition of '%s' fail, err: %+v", name, err)
+		}
+		if !bytes.Equal(newSch.GetSchemaDefText(), sch.GetSchemaDefText()) {
+			expDef, _ := sch.GetSchemaDef()
+			if !reflect.DeepEqual(normalizeSchemaDef(schDef), normalizeSchemaDef(expDef)) {
+				t.Errorf("round-trip schema definition of '%s' mismatch\n%s", name, doc)
+			}
+		}
+	}
+
+	sch, err := NewJSONPack().AddSchema("ranges", SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"a": {Type: "uint16le"},
+			"b": {Type: "int8", Optional: true},
+			"c": {Type: "doublebe"},
+		},
+		Order: []string{"a", "b", "c"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	doc, err := sch.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema fail, err: %+v", err)
+	}
+	expDoc := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "ranges",
+  "type": "object",
+  "properties": {
+    "a": {
+      "type": "integer",
+      "minimum": 0,
+      "maximum": 65535,
+      "x-jsonpack-type": "uint16le"
+    },
+    "b": {
+      "type": "integer",
+      "minimum": -128,
+      "maximum": 127,
+      "x-jsonpack-type": "int8"
+    },
+    "c": {
+      "type": "number",
+      "format": "double",
+      "x-jsonpack-type": "float64be"
+    }
+  },
+  "required": [
+    "a",
+    "c"
+  ]
+}`
+	if string(doc) != expDoc {
+		t.Errorf("ToJSONSchema result:\n%s\nexpect:\n%s", doc, expDoc)
+	}
+}
+
+// normalizeSchemaDef returns a copy of schema definition with canonical type names.
+func normalizeSchemaDef(schDef *SchemaDef) *SchemaDef {
+	newDef := cloneSchemaDef(schDef)
+	var normalize func(d *SchemaDef)
+	normalize = func(d *SchemaDef) {
+		if d == nil {
+			return
+		}
+		if opType, ok := builtinOpHandlerTypes[strings.ToLower(d.Type)]; ok {
+			d.Type = opTypeName(opType)
+		} else {
+			d.Type = strings.ToLower(d.Type)
+		}
+		normalize(d.Items)
+		for _, prop := range d.Properties {
+			normalize(prop)
+		}
+	}
+	normalize(newDef)
+	return newDef
+}
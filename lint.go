@@ -0,0 +1,186 @@
+package jsonpack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintSeverity represents the severity of lint issue.
+type LintSeverity string
+
+const (
+	// LintError indicates the schema definition can't be compiled, or it doesn't work as expected.
+	LintError LintSeverity = "error"
+	// LintWarning indicates the schema definition works, but it's likely a mistake or hard to maintain.
+	LintWarning LintSeverity = "warning"
+)
+
+// maximum depth of nested arrays without lint warning
+const lintMaxArrayDepth = 2
+
+// LintIssue represents an issue of schema definition found by Lint function.
+type LintIssue struct {
+	// property path, the items of array are represented as "[]", likes "accounts[].name",
+	// and it's empty for top-level schema definition
+	Path     string
+	Severity LintSeverity
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	path := i.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return fmt.Sprintf("%s: %s: %s", path, i.Severity, i.Message)
+}
+
+/*
+Lint checks schema definition def and returns the issues in the order of properties.
+
+The following issues are reported as errors:
+
+* The "type" is missing or unknown.
+
+* The object has "properties" but no "order", or "order" is empty.
+
+* The entry of "order" doesn't exist in "properties", or it appears more than once.
+
+* The array has no "items".
+
+The following issues are reported as warnings:
+
+* The property doesn't exist in "order", it's ignored by encoder and decoder.
+
+* The number types use both little-endian and big-endian byte orders in one schema definition.
+
+* The type name isn't canonical, likes "Uint32LE" or alias names likes "bool" and "doublele".
+
+* The arrays are nested more than two levels deep, likes "[[[int8]]]".
+
+* The object has no properties, it's encoded as zero bytes.
+
+Example:
+	for _, issue := range jsonpack.Lint(schDef) {
+		fmt.Println(issue)
+	}
+*/
+func Lint(def *SchemaDef) []LintIssue {
+	l := linter{issues: make([]LintIssue, 0)}
+	l.lint(def, "", 0)
+	return l.issues
+}
+
+// linter collects issues of schema definition.
+type linter struct {
+	issues []LintIssue
+	// the first number type which has byte order suffix, and its path
+	endianType string
+	endianPath string
+	// whether the mixed byte orders issue is reported
+	mixedEndian bool
+}
+
+func (l *linter) add(path string, severity LintSeverity, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lint(def *SchemaDef, path string, arrayDepth int) {
+	if def == nil {
+		l.add(path, LintError, "schema definition is empty")
+		return
+	}
+	if def.Type == "" {
+		l.add(path, LintError, "'type' is missing")
+		return
+	}
+
+	typ := strings.ToLower(def.Type)
+	switch typ {
+	case "object":
+		if def.Type != typ {
+			l.add(path, LintWarning, "type name '%s' isn't lowercase, use '%s'", def.Type, typ)
+		}
+		l.lintObject(def, path)
+
+	case "array":
+		if def.Type != typ {
+			l.add(path, LintWarning, "type name '%s' isn't lowercase, use '%s'", def.Type, typ)
+		}
+		if arrayDepth+1 > lintMaxArrayDepth && (def.Items == nil || strings.ToLower(def.Items.Type) != "array") {
+			l.add(path, LintWarning, "arrays are nested %d levels deep, consider using array of objects", arrayDepth+1)
+		}
+		if def.Items == nil {
+			l.add(path, LintError, "'items' of array is missing")
+			return
+		}
+		l.lint(def.Items, path+"[]", arrayDepth+1)
+
+	default:
+		opType, ok := builtinOpHandlerTypes[typ]
+		if !ok {
+			l.add(path, LintError, "unknown type '%s'", def.Type)
+			return
+		}
+		if name := opTypeName(opType); def.Type != name {
+			l.add(path, LintWarning, "type name '%s' isn't canonical, use '%s'", def.Type, name)
+		}
+		l.lintEndian(opTypeName(opType), path)
+	}
+}
+
+func (l *linter) lintObject(def *SchemaDef, path string) {
+	if len(def.Properties) == 0 && len(def.Order) == 0 {
+		l.add(path, LintWarning, "object has no properties, it's encoded as zero bytes")
+		return
+	}
+	if len(def.Order) == 0 {
+		l.add(path, LintError, "'order' of object is missing or empty")
+	}
+
+	inOrder := make(map[string]bool, len(def.Order))
+	for _, name := range def.Order {
+		propPath := formatPath(path, pathElem{name: name})
+		if inOrder[name] {
+			l.add(propPath, LintError, "'%s' appears more than once in 'order'", name)
+			continue
+		}
+		inOrder[name] = true
+
+		prop, ok := def.Properties[name]
+		if !ok {
+			l.add(propPath, LintError, "'%s' in 'order' doesn't exist in 'properties'", name)
+			continue
+		}
+		l.lint(prop, propPath, 0)
+	}
+
+	// the properties which are not in order, sorted by name for stable results
+	missing := make([]string, 0)
+	for name := range def.Properties {
+		if !inOrder[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		l.add(formatPath(path, pathElem{name: name}), LintWarning, "property '%s' doesn't exist in 'order', it's ignored", name)
+	}
+}
+
+// lintEndian reports mixed byte orders once, at the first number type which byte order differs from
+// the first number type with byte order suffix.
+func (l *linter) lintEndian(typeName string, path string) {
+	if l.mixedEndian || !(strings.HasSuffix(typeName, "le") || strings.HasSuffix(typeName, "be")) {
+		return
+	}
+	if l.endianType == "" {
+		l.endianType, l.endianPath = typeName, path
+		return
+	}
+	if typeName[len(typeName)-2:] != l.endianType[len(l.endianType)-2:] {
+		l.mixedEndian = true
+		l.add(path, LintWarning, "byte order of type '%s' differs from type '%s' at '%s'", typeName, l.endianType, l.endianPath)
+	}
+}
@@ -0,0 +1,85 @@
+package jsonpack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	schDef, err := jsonPack.GetSchema("complex").GetSchemaDef()
+	if err != nil {
+		t.Fatalf("GetSchemaDef fail, err: %+v", err)
+	}
+	if issues := Lint(schDef); len(issues) != 0 {
+		t.Errorf("Lint of valid schema definition got issues: %v", issues)
+	}
+
+	schDef = &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":   {Type: "String"},
+			"area":   {Type: "uint32le"},
+			"flag":   {Type: "bool"},
+			"code":   {Type: "uint16be"},
+			"ratio":  {Type: "doublebe"},
+			"extra":  {Type: "string"},
+			"list":   {Type: "array"},
+			"cube":   {Type: "array", Items: &SchemaDef{Type: "array", Items: &SchemaDef{Type: "array", Items: &SchemaDef{Type: "int8"}}}},
+			"user":   {Type: "object", Properties: map[string]*SchemaDef{"id": {Type: "int3"}, "nick": {}}, Order: []string{"id", "nick"}},
+			"unused": {Type: "int8"},
+			"empty":  {Type: "object"},
+			"point":  {Type: "object", Properties: map[string]*SchemaDef{"x": {Type: "int8"}}},
+		},
+		Order: []string{"name", "area", "flag", "code", "ratio", "area", "missing", "list", "cube", "user", "empty", "point"},
+	}
+	expIssues := []LintIssue{
+		{"name", LintWarning, "type name 'String' isn't canonical, use 'string'"},
+		{"flag", LintWarning, "type name 'bool' isn't canonical, use 'boolean'"},
+		{"code", LintWarning, "byte order of type 'uint16be' differs from type 'uint32le' at 'area'"},
+		{"ratio", LintWarning, "type name 'doublebe' isn't canonical, use 'float64be'"},
+		{"area", LintError, "'area' appears more than once in 'order'"},
+		{"missing", LintError, "'missing' in 'order' doesn't exist in 'properties'"},
+		{"list", LintError, "'items' of array is missing"},
+		{"cube[][]", LintWarning, "arrays are nested 3 levels deep, consider using array of objects"},
+		{"user.id", LintError, "unknown type 'int3'"},
+		{"user.nick", LintError, "'type' is missing"},
+		{"empty", LintWarning, "object has no properties, it's encoded as zero bytes"},
+		{"point", LintError, "'order' of object is missing or empty"},
+		{"point.x", LintWarning, "property 'x' doesn't exist in 'order', it's ignored"},
+		{"extra", LintWarning, "property 'extra' doesn't exist in 'order', it's ignored"},
+		{"unused", LintWarning, "property 'unused' doesn't exist in 'order', it's ignored"},
+	}
+	issues := Lint(schDef)
+	if !reflect.DeepEqual(issues, expIssues) {
+		t.Errorf("Lint result: %v, expect: %v", issues, expIssues)
+	}
+	// the compiler agrees with the issues
+	msgs, err := ParseIDL([]byte("message E {}"))
+	if err != nil {
+		t.Fatalf("ParseIDL fail, err: %+v", err)
+	}
+	if issues := Lint(msgs[0].Def); len(issues) != 1 || issues[0].Severity != LintWarning {
+		t.Errorf("Lint empty object, got: %v", issues)
+	}
+	if _, err = NewJSONPack().AddSchema("empty", msgs[0].Def); err != nil {
+		t.Errorf("AddSchema with empty object fail, err: %+v", err)
+	}
+	if _, err = NewJSONPack().AddSchema("point", schDef.Properties["point"]); err == nil {
+		t.Errorf("AddSchema with object without order should fail")
+	}
+	if issues[0].String() != "name: warning: type name 'String' isn't canonical, use 'string'" {
+		t.Errorf("LintIssue.String result: %s", issues[0].String())
+	}
+}
+
+func TestAddSchemaInvalidOrder(t *testing.T) {
+	invalidDefs := []string{
+		`{"type": "object", "properties": {"a": {"type": "string"}}, "order": ["a", "b"]}`,
+		`{"type": "object", "properties": {"a": {"type": "string"}}, "order": ["a", 1]}`,
+	}
+	for _, def := range invalidDefs {
+		if _, err := NewJSONPack().AddSchema("invalid", def); err == nil {
+			t.Errorf("AddSchema with invalid order should fail, def: %s", def)
+		}
+	}
+}
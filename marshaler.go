@@ -0,0 +1,108 @@
+package jsonpack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+	"github.com/pkg/errors"
+)
+
+/*
+Marshaler is the interface implemented by types that can encode themselves into jsonpack
+encoding format without reflection, likes the methods generated by jsonpack-parser tool
+with -g option.
+
+MarshalJSONPack encodes the value into buf from the beginning of buf, and returns the encoded data,
+it might re-allocate and grow buf if necessary.
+
+JSONPackFingerprint returns the fingerprint of schema definition which the methods are generated from,
+it's the result of SchemaFingerprint function.
+
+The Encode, EncodeTo and Marshal methods of Schema call MarshalJSONPack method instead of encoding
+the value with schema definition if the value implements this interface and the fingerprint is
+the same as the fingerprint of schema, otherwise the value is encoded with schema definition, e.g.
+the value is encoded with another version of schema.
+
+The generated MarshalJSONPack method returns error if the value has nil pointers, the same as
+encoding with schema definition.
+
+Example:
+	// generate MarshalJSONPack and UnmarshalJSONPack methods of Info struct into info_jsonpack.go
+	jsonpack-parser -s ./model -n Info -g -o ./model/info_jsonpack.go
+
+	// encodes info with generated MarshalJSONPack method
+	encodedResult, err := sch.Encode(&info)
+*/
+type Marshaler interface {
+	MarshalJSONPack(buf []byte) ([]byte, error)
+	JSONPackFingerprint() string
+}
+
+/*
+Unmarshaler is the interface implemented by types that can decode jsonpack encoded data
+into themselves without reflection, likes the methods generated by jsonpack-parser tool
+with -g option.
+
+The Decode and Unmarshal methods of Schema call UnmarshalJSONPack method instead of decoding
+data with schema definition if the target implements this interface and the fingerprint is the
+same as the fingerprint of schema, likes Marshaler.
+
+The DecodeProjection method always decodes data with schema definition.
+*/
+type Unmarshaler interface {
+	UnmarshalJSONPack(data []byte) error
+	JSONPackFingerprint() string
+}
+
+/*
+SchemaFingerprint returns the fingerprint of schema definition def, which is hex-encoded SHA-256 hash
+of the canonical form of def.
+
+The schema definitions which have the same encoding format have the same fingerprint, regardless of
+the letter case and aliases of type names, and the properties which don't exist in order.
+
+Example:
+	fingerprint := jsonpack.SchemaFingerprint(schDef)
+*/
+func SchemaFingerprint(def *SchemaDef) string {
+	// marshaling SchemaDef never fails, the keys of properties are sorted
+	data, _ := json.Marshal(canonicalSchemaDef(def))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// marshalerOf returns the Marshaler of d if d implements Marshaler and has the same fingerprint as s.
+func (s *Schema) marshalerOf(d interface{}) (Marshaler, bool) {
+	m, ok := d.(Marshaler)
+	if !ok || m.JSONPackFingerprint() != s.fingerprint {
+		return nil, false
+	}
+	return m, true
+}
+
+// unmarshalerOf returns the Unmarshaler of v if v implements Unmarshaler and has the same fingerprint as s.
+func (s *Schema) unmarshalerOf(v interface{}) (Unmarshaler, bool) {
+	u, ok := v.(Unmarshaler)
+	if !ok || u.JSONPackFingerprint() != s.fingerprint {
+		return nil, false
+	}
+	return u, true
+}
+
+// encodeMarshaler encodes d with MarshalJSONPack method into buf at current offset of buf.
+func (s *Schema) encodeMarshaler(buf *ibuf.Buffer, d Marshaler) error {
+	rest := buf.Bytes()[buf.Offset():]
+	data, err := d.MarshalJSONPack(rest)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// data is encoded in place if buf is large enough, no need to copy it
+	if len(data) > 0 && len(data) <= len(rest) && &data[0] == &rest[0] {
+		buf.SeekUnsafe(int64(len(data)), true)
+		return nil
+	}
+	buf.WriteBytes(data)
+	return nil
+}
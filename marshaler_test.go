@@ -0,0 +1,263 @@
+package jsonpack
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+	"github.com/pkg/errors"
+)
+
+type plainPoint struct {
+	X    int32    `json:"x"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// genPoint has the same fields as plainPoint, and the methods are the same as generated by jsonpack-parser
+type genPoint plainPoint
+
+// the fingerprint of little endian schema of plainPoint
+var genPointFingerprint = SchemaFingerprint(&SchemaDef{
+	Type: "object",
+	Properties: map[string]*SchemaDef{
+		"x":    {Type: "int32le"},
+		"name": {Type: "string"},
+		"tags": {Type: "array", Items: &SchemaDef{Type: "string"}},
+	},
+	Order: []string{"x", "name", "tags"},
+})
+
+var genPointCalls struct {
+	marshal   int
+	unmarshal int
+}
+
+func (v genPoint) MarshalJSONPack(buf []byte) ([]byte, error) {
+	genPointCalls.marshal++
+	if v.Name == "invalid" {
+		return nil, errors.New("invalid name")
+	}
+	b := ibuf.From(buf)
+	b.WriteInt32LE(v.X)
+	s0 := v.Name
+	b.WriteVarUint(uint64(len(s0)))
+	b.WriteBytes([]byte(s0))
+	b.WriteVarUint(uint64(len(v.Tags)))
+	for i1 := range v.Tags {
+		s2 := v.Tags[i1]
+		b.WriteVarUint(uint64(len(s2)))
+		b.WriteBytes([]byte(s2))
+	}
+	return b.Seal(), nil
+}
+
+func (genPoint) JSONPackFingerprint() string {
+	return genPointFingerprint
+}
+
+func (v *genPoint) UnmarshalJSONPack(data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decode genPoint got error: %v", r)
+		}
+	}()
+
+	genPointCalls.unmarshal++
+	b := ibuf.From(data)
+	v.X = b.ReadInt32LE()
+	v.Name = b.ReadString()
+	l0, c1 := b.ReadVarUint()
+	if c1 <= 0 || l0 > uint64(b.Capacity()-b.Offset()) {
+		return fmt.Errorf("decode genPoint got invalid length of 'tags'")
+	}
+	n2 := int(l0)
+	if v.Tags != nil && cap(v.Tags) >= n2 {
+		v.Tags = v.Tags[:n2]
+	} else {
+		v.Tags = make([]string, n2)
+	}
+	for i3 := 0; i3 < n2; i3++ {
+		v.Tags[i3] = b.ReadString()
+	}
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	jsonPack := NewJSONPack()
+	sch, err := jsonPack.AddSchema("point", plainPoint{}, LittleEndian)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	plain := plainPoint{X: -7, Name: "origin", Tags: []string{"a", "bc"}}
+	expData, err := sch.Encode(&plain)
+	if err != nil {
+		t.Fatalf("Encode plainPoint fail, err: %+v", err)
+	}
+
+	gen := genPoint(plain)
+	calls := genPointCalls.marshal
+	for _, v := range []interface{}{gen, &gen} {
+		data, err := sch.Encode(v)
+		if err != nil {
+			t.Fatalf("Encode %T fail, err: %+v", v, err)
+		}
+		if !bytes.Equal(data, expData) {
+			t.Errorf("Encode %T result: %v, expect: %v", v, data, expData)
+		}
+	}
+	if n := genPointCalls.marshal - calls; n != 2 {
+		t.Errorf("MarshalJSONPack is called %d times, expect: 2", n)
+	}
+
+	// the buffer which is too small to hold encoded data and the buffer with enough capacity
+	for _, size := range []int{2, 256} {
+		data := make([]byte, size)
+		err = sch.EncodeTo(&gen, &data)
+		if err != nil {
+			t.Fatalf("EncodeTo with %d bytes buffer fail, err: %+v", size, err)
+		}
+		if !bytes.Equal(data, expData) {
+			t.Errorf("EncodeTo with %d bytes buffer result: %v, expect: %v", size, data, expData)
+		}
+	}
+
+	// the schema which has different byte order encodes with schema definition
+	beSch, err := jsonPack.AddSchema("pointBE", plainPoint{}, BigEndian)
+	if err != nil {
+		t.Fatalf("AddSchema with big endian fail, err: %+v", err)
+	}
+	expData, err = beSch.Encode(&plain)
+	if err != nil {
+		t.Fatalf("Encode plainPoint with big endian fail, err: %+v", err)
+	}
+	calls = genPointCalls.marshal
+	data, err := beSch.Encode(&gen)
+	if err != nil {
+		t.Fatalf("Encode genPoint with big endian fail, err: %+v", err)
+	}
+	if !bytes.Equal(data, expData) {
+		t.Errorf("Encode genPoint with big endian result: %v, expect: %v", data, expData)
+	}
+	if n := genPointCalls.marshal - calls; n != 0 {
+		t.Errorf("MarshalJSONPack is called %d times with big endian schema, expect: 0", n)
+	}
+
+	invalid := genPoint{Name: "invalid"}
+	_, err = sch.Encode(&invalid)
+	var encodeErr *EncodeError
+	if !errors.As(err, &encodeErr) {
+		t.Errorf("Encode with failed MarshalJSONPack should return EncodeError, got: %v", err)
+	}
+}
+
+func TestUnmarshaler(t *testing.T) {
+	jsonPack := NewJSONPack()
+	sch, err := jsonPack.AddSchema("point", plainPoint{}, LittleEndian)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	plain := plainPoint{X: 12, Name: "point", Tags: []string{"x"}}
+	data, err := sch.Encode(&plain)
+	if err != nil {
+		t.Fatalf("Encode plainPoint fail, err: %+v", err)
+	}
+
+	calls := genPointCalls.unmarshal
+	gen := genPoint{Tags: []string{"old", "tags"}}
+	err = sch.Decode(data, &gen)
+	if err != nil {
+		t.Fatalf("Decode genPoint fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(plainPoint(gen), plain) {
+		t.Errorf("Decode genPoint result: %+v, expect: %+v", gen, plain)
+	}
+	if n := genPointCalls.unmarshal - calls; n != 1 {
+		t.Errorf("UnmarshalJSONPack is called %d times, expect: 1", n)
+	}
+
+	// projection decoding doesn't use UnmarshalJSONPack
+	gen = genPoint{}
+	err = sch.DecodeProjection(data, &gen)
+	if err != nil {
+		t.Fatalf("DecodeProjection genPoint fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(plainPoint(gen), plain) {
+		t.Errorf("DecodeProjection genPoint result: %+v, expect: %+v", gen, plain)
+	}
+	if n := genPointCalls.unmarshal - calls; n != 1 {
+		t.Errorf("UnmarshalJSONPack is called %d times, expect: 1", n)
+	}
+
+	// the schema which has different byte order decodes with schema definition
+	beSch, err := jsonPack.AddSchema("pointBE", plainPoint{}, BigEndian)
+	if err != nil {
+		t.Fatalf("AddSchema with big endian fail, err: %+v", err)
+	}
+	beData, err := beSch.Encode(&plain)
+	if err != nil {
+		t.Fatalf("Encode plainPoint with big endian fail, err: %+v", err)
+	}
+	gen = genPoint{}
+	err = beSch.Decode(beData, &gen)
+	if err != nil {
+		t.Fatalf("Decode genPoint with big endian fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(plainPoint(gen), plain) {
+		t.Errorf("Decode genPoint with big endian result: %+v, expect: %+v", gen, plain)
+	}
+	if n := genPointCalls.unmarshal - calls; n != 1 {
+		t.Errorf("UnmarshalJSONPack is called %d times, expect: 1", n)
+	}
+
+	err = sch.Decode(data[:3], &gen)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Decode truncated data should return DecodeError, got: %v", err)
+	}
+
+	// the length of tags is much larger than the remaining bytes
+	overlong := []byte{0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0x0f}
+	err = sch.Decode(overlong, &gen)
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Decode data with overlong length should return DecodeError, got: %v", err)
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	sch, err := NewJSONPack().AddSchema("point", plainPoint{}, LittleEndian)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	schDef, err := sch.GetSchemaDef()
+	if err != nil {
+		t.Fatalf("GetSchemaDef fail, err: %+v", err)
+	}
+	if fingerprint := SchemaFingerprint(schDef); fingerprint != genPointFingerprint {
+		t.Errorf("fingerprint of schema: %s, expect: %s", fingerprint, genPointFingerprint)
+	}
+
+	// the aliases of type names and the properties which don't exist in order
+	aliasDef := &SchemaDef{
+		Type: "Object",
+		Properties: map[string]*SchemaDef{
+			"x":       {Type: "Int32LE"},
+			"name":    {Type: "string"},
+			"tags":    {Type: "array", Items: &SchemaDef{Type: "string"}},
+			"ignored": {Type: "bool"},
+		},
+		Order: []string{"x", "name", "tags"},
+	}
+	if fingerprint := SchemaFingerprint(aliasDef); fingerprint != genPointFingerprint {
+		t.Errorf("fingerprint of schema with aliases: %s, expect: %s", fingerprint, genPointFingerprint)
+	}
+
+	aliasDef.Properties["x"].Type = "int32be"
+	if fingerprint := SchemaFingerprint(aliasDef); fingerprint == genPointFingerprint {
+		t.Errorf("fingerprint of schema with different byte order should be different")
+	}
+}
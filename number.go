@@ -0,0 +1,319 @@
+package jsonpack
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+// numberKind represents the kind of number value
+type numberKind uint8
+
+const (
+	signedNumber numberKind = iota
+	unsignedNumber
+	floatNumber
+)
+
+// number represents a numeric value of any builtin number type.
+type number struct {
+	kind numberKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+func (n number) String() string {
+	switch n.kind {
+	case signedNumber:
+		return fmt.Sprintf("%d", n.i)
+	case unsignedNumber:
+		return fmt.Sprintf("%d", n.u)
+	default:
+		return fmt.Sprintf("%g", n.f)
+	}
+}
+
+// toInt converts number to signed integer in the range from min to max.
+func (n number) toInt(min, max int64) (int64, bool) {
+	switch n.kind {
+	case signedNumber:
+		return n.i, n.i >= min && n.i <= max
+	case unsignedNumber:
+		return int64(n.u), n.u <= uint64(max)
+	default:
+		ok := n.f == math.Trunc(n.f) && n.f >= float64(min) && n.f < float64(max)+1
+		return int64(n.f), ok
+	}
+}
+
+// toUint converts number to unsigned integer in the range from 0 to max.
+func (n number) toUint(max uint64) (uint64, bool) {
+	switch n.kind {
+	case signedNumber:
+		return uint64(n.i), n.i >= 0 && uint64(n.i) <= max
+	case unsignedNumber:
+		return n.u, n.u <= max
+	default:
+		ok := n.f == math.Trunc(n.f) && n.f >= 0 && n.f < float64(max)+1
+		return uint64(n.f), ok
+	}
+}
+
+// toFloat converts number to floating number.
+func (n number) toFloat() float64 {
+	switch n.kind {
+	case signedNumber:
+		return float64(n.i)
+	case unsignedNumber:
+		return float64(n.u)
+	default:
+		return n.f
+	}
+}
+
+// toNumber converts value of any Go number type to number.
+func toNumber(v interface{}) (number, bool) {
+	switch v := v.(type) {
+	case int:
+		return number{kind: signedNumber, i: int64(v)}, true
+	case int8:
+		return number{kind: signedNumber, i: int64(v)}, true
+	case int16:
+		return number{kind: signedNumber, i: int64(v)}, true
+	case int32:
+		return number{kind: signedNumber, i: int64(v)}, true
+	case int64:
+		return number{kind: signedNumber, i: v}, true
+	case uint:
+		return number{kind: unsignedNumber, u: uint64(v)}, true
+	case uint8:
+		return number{kind: unsignedNumber, u: uint64(v)}, true
+	case uint16:
+		return number{kind: unsignedNumber, u: uint64(v)}, true
+	case uint32:
+		return number{kind: unsignedNumber, u: uint64(v)}, true
+	case uint64:
+		return number{kind: unsignedNumber, u: v}, true
+	case float32:
+		return number{kind: floatNumber, f: float64(v)}, true
+	case float64:
+		return number{kind: floatNumber, f: v}, true
+	}
+	return number{}, false
+}
+
+// parseNumber converts JSON number literal s to number, the integers are converted exactly
+// instead of converting to floating number.
+func parseNumber(s string) (number, bool) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: signedNumber, i: i}, true
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return number{kind: unsignedNumber, u: u}, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return number{kind: floatNumber, f: f}, err == nil
+}
+
+// goValue converts n to the Go value of handler type typ, which has the same Go type as the value
+// decoded into map, it returns false if n can't be represented by typ without overflow.
+func (n number) goValue(typ opHandlerType) (interface{}, bool) {
+	switch typ {
+	case int8OpType:
+		i, ok := n.toInt(math.MinInt8, math.MaxInt8)
+		return int8(i), ok
+	case int16LEOpType, int16BEOpType:
+		i, ok := n.toInt(math.MinInt16, math.MaxInt16)
+		return int16(i), ok
+	case int32LEOpType, int32BEOpType:
+		i, ok := n.toInt(math.MinInt32, math.MaxInt32)
+		return int32(i), ok
+	case int64LEOpType, int64BEOpType:
+		return n.toInt(math.MinInt64, math.MaxInt64)
+	case uint8OpType:
+		u, ok := n.toUint(math.MaxUint8)
+		return uint8(u), ok
+	case uint16LEOpType, uint16BEOpType:
+		u, ok := n.toUint(math.MaxUint16)
+		return uint16(u), ok
+	case uint32LEOpType, uint32BEOpType:
+		u, ok := n.toUint(math.MaxUint32)
+		return uint32(u), ok
+	case uint64LEOpType, uint64BEOpType:
+		return n.toUint(math.MaxUint64)
+	case float32LEOpType, float32BEOpType:
+		f := n.toFloat()
+		return float32(f), math.IsInf(f, 0) || math.IsNaN(f) || math.Abs(f) <= math.MaxFloat32
+	case float64LEOpType, float64BEOpType:
+		return n.toFloat(), true
+	}
+	return nil, false
+}
+
+/*
+ConvertNumber converts v, which is a value of any Go number type, to the Go type of number type typ,
+likes int16 for "int16le" type, it's the same type as the value decoded into map.
+
+It returns *UnknownTypeError error if typ isn't a builtin type, *TypeAssertionError error if typ isn't
+a number type or v isn't a number, or error if v can't be represented by typ without overflow.
+
+Example:
+	v, err := jsonpack.ConvertNumber("uint16le", int64(200))
+*/
+func ConvertNumber(typ string, v interface{}) (interface{}, error) {
+	opType, ok := builtinOpHandlerTypes[strings.ToLower(typ)]
+	if !ok {
+		return nil, errors.WithStack(&UnknownTypeError{typ})
+	}
+	n, ok := toNumber(v)
+	if !ok || !isNumberType(opType) {
+		return nil, errors.WithStack(&TypeAssertionError{v, typ})
+	}
+	val, ok := n.goValue(opType)
+	if !ok {
+		return nil, errors.Errorf("value %s overflows %s type", n, opTypeName(opType))
+	}
+	return val, nil
+}
+
+// isNumberType reports whether handler type is a builtin number type.
+func isNumberType(typ opHandlerType) bool {
+	return typ >= int8OpType && typ <= float64BEOpType
+}
+
+// readNumber reads number with handler type typ from buf.
+func readNumber(buf *ibuf.Buffer, typ opHandlerType) number {
+	switch typ {
+	case int8OpType:
+		return number{kind: signedNumber, i: int64(buf.ReadInt8())}
+	case int16LEOpType:
+		return number{kind: signedNumber, i: int64(buf.ReadInt16LE())}
+	case int16BEOpType:
+		return number{kind: signedNumber, i: int64(buf.ReadInt16BE())}
+	case int32LEOpType:
+		return number{kind: signedNumber, i: int64(buf.ReadInt32LE())}
+	case int32BEOpType:
+		return number{kind: signedNumber, i: int64(buf.ReadInt32BE())}
+	case int64LEOpType:
+		return number{kind: signedNumber, i: buf.ReadInt64LE()}
+	case int64BEOpType:
+		return number{kind: signedNumber, i: buf.ReadInt64BE()}
+	case uint8OpType:
+		return number{kind: unsignedNumber, u: uint64(buf.ReadUint8())}
+	case uint16LEOpType:
+		return number{kind: unsignedNumber, u: uint64(buf.ReadUint16LE())}
+	case uint16BEOpType:
+		return number{kind: unsignedNumber, u: uint64(buf.ReadUint16BE())}
+	case uint32LEOpType:
+		return number{kind: unsignedNumber, u: uint64(buf.ReadUint32LE())}
+	case uint32BEOpType:
+		return number{kind: unsignedNumber, u: uint64(buf.ReadUint32BE())}
+	case uint64LEOpType:
+		return number{kind: unsignedNumber, u: buf.ReadUint64LE()}
+	case uint64BEOpType:
+		return number{kind: unsignedNumber, u: buf.ReadUint64BE()}
+	case float32LEOpType:
+		return number{kind: floatNumber, f: float64(buf.ReadFloat32LE())}
+	case float32BEOpType:
+		return number{kind: floatNumber, f: float64(buf.ReadFloat32BE())}
+	case float64LEOpType:
+		return number{kind: floatNumber, f: buf.ReadFloat64LE()}
+	case float64BEOpType:
+		return number{kind: floatNumber, f: buf.ReadFloat64BE()}
+	}
+	panic(&UnknownTypeError{opTypeName(typ)})
+}
+
+// writeNumber writes n into buf with handler type typ,
+// it returns error if n can't be represented by typ without overflow.
+func writeNumber(buf *ibuf.Buffer, typ opHandlerType, n number) error {
+	var ok bool
+	var i int64
+	var u uint64
+
+	switch typ {
+	case int8OpType:
+		if i, ok = n.toInt(math.MinInt8, math.MaxInt8); ok {
+			buf.WriteByte(byte(i))
+		}
+	case int16LEOpType:
+		if i, ok = n.toInt(math.MinInt16, math.MaxInt16); ok {
+			buf.WriteInt16LE(int16(i))
+		}
+	case int16BEOpType:
+		if i, ok = n.toInt(math.MinInt16, math.MaxInt16); ok {
+			buf.WriteInt16BE(int16(i))
+		}
+	case int32LEOpType:
+		if i, ok = n.toInt(math.MinInt32, math.MaxInt32); ok {
+			buf.WriteInt32LE(int32(i))
+		}
+	case int32BEOpType:
+		if i, ok = n.toInt(math.MinInt32, math.MaxInt32); ok {
+			buf.WriteInt32BE(int32(i))
+		}
+	case int64LEOpType:
+		if i, ok = n.toInt(math.MinInt64, math.MaxInt64); ok {
+			buf.WriteInt64LE(i)
+		}
+	case int64BEOpType:
+		if i, ok = n.toInt(math.MinInt64, math.MaxInt64); ok {
+			buf.WriteInt64BE(i)
+		}
+	case uint8OpType:
+		if u, ok = n.toUint(math.MaxUint8); ok {
+			buf.WriteByte(byte(u))
+		}
+	case uint16LEOpType:
+		if u, ok = n.toUint(math.MaxUint16); ok {
+			buf.WriteUint16LE(uint16(u))
+		}
+	case uint16BEOpType:
+		if u, ok = n.toUint(math.MaxUint16); ok {
+			buf.WriteUint16BE(uint16(u))
+		}
+	case uint32LEOpType:
+		if u, ok = n.toUint(math.MaxUint32); ok {
+			buf.WriteUint32LE(uint32(u))
+		}
+	case uint32BEOpType:
+		if u, ok = n.toUint(math.MaxUint32); ok {
+			buf.WriteUint32BE(uint32(u))
+		}
+	case uint64LEOpType:
+		if u, ok = n.toUint(math.MaxUint64); ok {
+			buf.WriteUint64LE(u)
+		}
+	case uint64BEOpType:
+		if u, ok = n.toUint(math.MaxUint64); ok {
+			buf.WriteUint64BE(u)
+		}
+	case float32LEOpType, float32BEOpType:
+		f := n.toFloat()
+		ok = math.IsInf(f, 0) || math.IsNaN(f) || math.Abs(f) <= math.MaxFloat32
+		if ok && typ == float32LEOpType {
+			buf.WriteFloat32LE(float32(f))
+		} else if ok {
+			buf.WriteFloat32BE(float32(f))
+		}
+	case float64LEOpType:
+		buf.WriteFloat64LE(n.toFloat())
+		ok = true
+	case float64BEOpType:
+		buf.WriteFloat64BE(n.toFloat())
+		ok = true
+	default:
+		return errors.WithStack(&UnknownTypeError{opTypeName(typ)})
+	}
+
+	if !ok {
+		return errors.Errorf("value %s overflows %s type", n, opTypeName(typ))
+	}
+	return nil
+}
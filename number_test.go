@@ -0,0 +1,69 @@
+package jsonpack
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCanonicalType(t *testing.T) {
+	tests := map[string]string{
+		"bool":     "boolean",
+		"doubleLE": "float64le",
+		"FloatBE":  "float32be",
+		"uint16le": "uint16le",
+		"int8":     "int8",
+	}
+	for typ, expType := range tests {
+		canonical, ok := CanonicalType(typ)
+		if !ok || canonical != expType {
+			t.Errorf("CanonicalType(%s): %s, %v, expect: %s", typ, canonical, ok, expType)
+		}
+	}
+	for _, typ := range []string{"int16", "object", "array", "unknown"} {
+		if _, ok := CanonicalType(typ); ok {
+			t.Errorf("CanonicalType(%s) should fail", typ)
+		}
+	}
+}
+
+func TestConvertNumber(t *testing.T) {
+	tests := []struct {
+		typ    string
+		v      interface{}
+		expect interface{}
+	}{
+		{"int8", int64(-128), int8(-128)},
+		{"uint16le", float64(200), uint16(200)},
+		{"Int64BE", uint64(math.MaxInt64), int64(math.MaxInt64)},
+		{"uint64le", uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{"floatle", int32(3), float32(3)},
+		{"doublebe", uint8(7), float64(7)},
+	}
+	for _, test := range tests {
+		v, err := ConvertNumber(test.typ, test.v)
+		if err != nil {
+			t.Errorf("ConvertNumber(%s, %v) fail, err: %+v", test.typ, test.v, err)
+			continue
+		}
+		if v != test.expect {
+			t.Errorf("ConvertNumber(%s, %v): %v (%T), expect: %v (%T)", test.typ, test.v, v, v, test.expect, test.expect)
+		}
+	}
+
+	var typeErr *TypeAssertionError
+	var unknownErr *UnknownTypeError
+	if _, err := ConvertNumber("uint8", int64(-1)); err == nil {
+		t.Errorf("ConvertNumber with overflow value should fail")
+	}
+	if _, err := ConvertNumber("int32le", 1.5); err == nil {
+		t.Errorf("ConvertNumber with fraction should fail")
+	}
+	if _, err := ConvertNumber("string", int64(1)); !errors.As(err, &typeErr) {
+		t.Errorf("ConvertNumber with string type should return TypeAssertionError, got: %v", err)
+	}
+	if _, err := ConvertNumber("int16", int64(1)); !errors.As(err, &unknownErr) {
+		t.Errorf("ConvertNumber with unknown type should return UnknownTypeError, got: %v", err)
+	}
+}
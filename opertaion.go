@@ -9,6 +9,8 @@ type operation struct {
 	handler     opHandler
 	handlerType opHandlerType
 	children    []*operation
+	// byte size of encoded value if the size is fixed, or -1 if it's variable length
+	fixedSize int64
 }
 
 type structOperation struct {
@@ -22,7 +24,32 @@ type structOperation struct {
 }
 
 func newOperation(field string, handler opHandler, handlerType opHandlerType) *operation {
-	return &operation{field, handler, handlerType, make([]*operation, 0)}
+	return &operation{field, handler, handlerType, make([]*operation, 0), -1}
+}
+
+// updateFixedSize calculates byte size of encoded value of operation and its children.
+func (o *operation) updateFixedSize() int64 {
+	switch o.handlerType {
+	case objectOpType:
+		var size int64
+		for _, child := range o.children {
+			childSize := child.updateFixedSize()
+			if childSize < 0 || size < 0 {
+				size = -1
+			} else {
+				size += childSize
+			}
+		}
+		o.fixedSize = size
+	case sliceOpType, arrayOpType:
+		for _, child := range o.children {
+			child.updateFixedSize()
+		}
+		o.fixedSize = -1
+	default:
+		o.fixedSize = builtinFixedSize(o.handlerType)
+	}
+	return o.fixedSize
 }
 
 func newStructOperation(handler opHandler, handlerType opHandlerType) *structOperation {
@@ -36,3 +63,12 @@ func newStructOperation(handler opHandler, handlerType opHandlerType) *structOpe
 func (s *structOperation) appendChild(child *structOperation) {
 	s.children = append(s.children, child)
 }
+
+// displayName returns property name of operation for messages, or "item" for items of array
+// which don't have property name.
+func (o *operation) displayName() string {
+	if o.propName == "" {
+		return "item"
+	}
+	return o.propName
+}
@@ -0,0 +1,336 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+// SuggestionKind represents the kind of schema optimization suggestion.
+type SuggestionKind string
+
+const (
+	// SuggestNarrow suggests a narrower integer type that fits all observed values.
+	SuggestNarrow SuggestionKind = "narrow"
+	// SuggestFloat32 suggests float32 type for float64 property which values can be represented by float32 exactly.
+	SuggestFloat32 SuggestionKind = "float32"
+	// SuggestVarint suggests variable-length integer encoding for the integer property which values are mostly small.
+	SuggestVarint SuggestionKind = "varint"
+	// SuggestEnum suggests enumeration of number type for the string property which has few distinct values.
+	SuggestEnum SuggestionKind = "enum"
+)
+
+// maximum number of distinct string values for enum suggestion
+const optimizeEnumLimit = 256
+
+// Suggestion represents a suggestion of schema optimization.
+type Suggestion struct {
+	// property path, the items of array are represented as "[]", likes "accounts[].name"
+	Path string
+	Kind SuggestionKind
+	// current type of property
+	From string
+	// suggested type of property, it's "varint" and "enum" for advisory suggestions
+	To string
+	// whether the suggestion is applied to the schema definition returned by Optimize
+	Applied bool
+	// estimated byte savings over all samples
+	Savings int64
+	// description of the evidence of suggestion
+	Message string
+}
+
+// optimizeStats holds the observed values of a property.
+type optimizeStats struct {
+	path   string
+	schDef *SchemaDef
+	count  int64
+
+	// number values
+	hasInt  bool
+	isFloat bool
+	minInt  int64
+	maxInt  int64
+	// maximum value if it exceeds the range of int64
+	maxUint uint64
+	// encoded bytes of values in unsigned varint and in zigzag encoded signed varint
+	varUintBytes int64
+	varIntBytes  int64
+	// whether all values can be represented by float32 exactly
+	float32Exact bool
+
+	// string values
+	distinct    map[string]struct{}
+	stringBytes int64
+}
+
+/*
+Optimize analyzes the sample values with schema definition def, and returns an optimized copy
+of def and the suggestions with estimated byte savings, def will not be modified.
+
+The samples can be map[string]interface{}, []interface{}, structs, or []byte of JSON documents,
+the samples which can't be converted to JSON are ignored.
+
+The following suggestions are applied to the returned schema definition:
+
+* Narrowing: the integer property uses the narrowest integer type of the same byte order that fits all
+observed values, likes "uint32le" to "uint8".
+
+* Float32: the float64 property which all observed values can be represented by float32 exactly.
+
+The following suggestions are advisory only, because they need types that schema definition doesn't support:
+
+* Varint: the integer property which observed values take less bytes in variable-length encoding.
+
+* Enum: the string property which has few distinct values, and could be represented as a number.
+
+The narrowed types only fit the observed values, use samples which cover the range of values.
+
+Example:
+	schDef, _ := sch.GetSchemaDef()
+	optDef, suggestions := jsonpack.Optimize(schDef, samples)
+	for _, s := range suggestions {
+		fmt.Printf("%s: %s -> %s, saves %d bytes\n", s.Path, s.From, s.To, s.Savings)
+	}
+*/
+func Optimize(def *SchemaDef, samples []interface{}) (*SchemaDef, []Suggestion) {
+	optDef := cloneSchemaDef(def)
+
+	stats := make(map[string]*optimizeStats)
+	order := make([]*optimizeStats, 0)
+	for _, sample := range samples {
+		v, err := toJSONValue(sample)
+		if err != nil {
+			continue
+		}
+		collectOptimizeStats(optDef, v, "", stats, &order)
+	}
+
+	suggestions := make([]Suggestion, 0)
+	for _, st := range order {
+		suggestions = append(suggestions, st.suggest()...)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Savings > suggestions[j].Savings
+	})
+	return optDef, suggestions
+}
+
+// toJSONValue converts v to the value decoded from JSON document, the numbers are json.Number.
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, ok := v.([]byte)
+	if !ok {
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := dec.Decode(&result)
+	return result, err
+}
+
+func collectOptimizeStats(schDef *SchemaDef, v interface{}, path string, stats map[string]*optimizeStats, order *[]*optimizeStats) {
+	if schDef == nil || v == nil {
+		return
+	}
+
+	typ := strings.ToLower(schDef.Type)
+	switch typ {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, name := range schDef.Order {
+			collectOptimizeStats(schDef.Properties[name], m[name], formatPath(path, pathElem{name: name}), stats, order)
+		}
+		return
+
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			collectOptimizeStats(schDef.Items, item, path+"[]", stats, order)
+		}
+		return
+	}
+
+	st, ok := stats[path]
+	if !ok {
+		st = &optimizeStats{path: path, schDef: schDef, float32Exact: true, distinct: make(map[string]struct{})}
+		stats[path] = st
+		*order = append(*order, st)
+	}
+
+	switch v := v.(type) {
+	case string:
+		st.count++
+		st.stringBytes += ibuf.ByteLenVarUint(uint64(len(v))) + int64(len(v))
+		if len(st.distinct) <= optimizeEnumLimit {
+			st.distinct[v] = struct{}{}
+		}
+
+	case json.Number:
+		st.count++
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			if !st.hasInt || i < st.minInt {
+				st.minInt = i
+			}
+			if !st.hasInt || i > st.maxInt {
+				st.maxInt = i
+			}
+			st.hasInt = true
+			st.varIntBytes += ibuf.ByteLenVarInt(i)
+			if i >= 0 {
+				st.varUintBytes += ibuf.ByteLenVarUint(uint64(i))
+			}
+		} else if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			if !st.hasInt {
+				st.minInt = math.MaxInt64
+			}
+			st.hasInt = true
+			if u > st.maxUint {
+				st.maxUint = u
+			}
+			// the value exceeds the range of int64 takes 10 bytes in both encodings
+			st.varUintBytes += ibuf.ByteLenVarUint(u)
+			st.varIntBytes += ibuf.ByteLenVarUint(u)
+		} else {
+			st.isFloat = true
+		}
+		if f, err := v.Float64(); err != nil || float64(float32(f)) != f {
+			st.float32Exact = false
+		}
+	}
+}
+
+// suggest returns suggestions of property, and applies narrowing and float32 suggestions
+// to schema definition.
+func (st *optimizeStats) suggest() []Suggestion {
+	suggestions := make([]Suggestion, 0)
+	if st.count == 0 {
+		return suggestions
+	}
+
+	typ := strings.ToLower(st.schDef.Type)
+	opType, ok := builtinOpHandlerTypes[typ]
+	if !ok {
+		return suggestions
+	}
+	from := opTypeName(opType)
+	size := builtinFixedSize(opType)
+	bigEndian := strings.HasSuffix(from, "be")
+
+	switch {
+	case opType == stringOpType:
+		distinct := int64(len(st.distinct))
+		if distinct <= optimizeEnumLimit && distinct < st.count {
+			// the index of enumeration fits in uint8
+			if savings := st.stringBytes - st.count; savings > 0 {
+				suggestions = append(suggestions, Suggestion{
+					Path: st.path, Kind: SuggestEnum, From: from, To: "enum", Savings: savings,
+					Message: fmt.Sprintf("%d distinct values in %d values", distinct, st.count),
+				})
+			}
+		}
+
+	case isNumberType(opType) && opType < float32LEOpType:
+		// integer types
+		if st.isFloat {
+			return suggestions
+		}
+		signed := opType <= int64BEOpType
+		maxUint, maxStr := st.maxUint, strconv.FormatUint(st.maxUint, 10)
+		if maxUint == 0 {
+			maxStr = strconv.FormatInt(st.maxInt, 10)
+			if st.maxInt > 0 {
+				maxUint = uint64(st.maxInt)
+			}
+		}
+		narrowType := narrowestIntType(st.minInt, maxUint, signed, bigEndian)
+		if narrowSize := builtinFixedSize(builtinOpHandlerTypes[narrowType]); narrowType != "" && narrowSize < size {
+			suggestions = append(suggestions, Suggestion{
+				Path: st.path, Kind: SuggestNarrow, From: from, To: narrowType, Applied: true,
+				Savings: (size - narrowSize) * st.count,
+				Message: fmt.Sprintf("values range from %d to %s", st.minInt, maxStr),
+			})
+			st.schDef.Type = narrowType
+			size = narrowSize
+		}
+		// the values of signed type are zigzag encoded
+		varBytes := st.varUintBytes
+		if signed {
+			varBytes = st.varIntBytes
+		}
+		if savings := size*st.count - varBytes; savings > 0 {
+			suggestions = append(suggestions, Suggestion{
+				Path: st.path, Kind: SuggestVarint, From: st.schDef.Type, To: "varint", Savings: savings,
+				Message: fmt.Sprintf("average %.2f bytes per value in varint encoding", float64(varBytes)/float64(st.count)),
+			})
+		}
+
+	case opType == float64LEOpType || opType == float64BEOpType:
+		if st.float32Exact {
+			to := "float32le"
+			if bigEndian {
+				to = "float32be"
+			}
+			suggestions = append(suggestions, Suggestion{
+				Path: st.path, Kind: SuggestFloat32, From: from, To: to, Applied: true, Savings: 4 * st.count,
+				Message: "all values can be represented by float32 exactly",
+			})
+			st.schDef.Type = to
+		}
+	}
+	return suggestions
+}
+
+// narrowestIntType returns the narrowest integer type which fits the range from minInt to maxUint,
+// or returns empty string if there's no integer type fits.
+func narrowestIntType(minInt int64, maxUint uint64, signed bool, bigEndian bool) string {
+	suffix := "le"
+	if bigEndian {
+		suffix = "be"
+	}
+
+	if !signed {
+		if minInt < 0 {
+			return ""
+		}
+		switch {
+		case maxUint <= math.MaxUint8:
+			return "uint8"
+		case maxUint <= math.MaxUint16:
+			return "uint16" + suffix
+		case maxUint <= math.MaxUint32:
+			return "uint32" + suffix
+		default:
+			return "uint64" + suffix
+		}
+	}
+
+	switch {
+	case minInt >= math.MinInt8 && maxUint <= math.MaxInt8:
+		return "int8"
+	case minInt >= math.MinInt16 && maxUint <= math.MaxInt16:
+		return "int16" + suffix
+	case minInt >= math.MinInt32 && maxUint <= math.MaxInt32:
+		return "int32" + suffix
+	case maxUint <= math.MaxInt64:
+		return "int64" + suffix
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package jsonpack
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	schDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"id":     {Type: "uint32be"},
+			"status": {Type: "string"},
+			"ratio":  {Type: "float64le"},
+			"score":  {Type: "int64le"},
+			"items":  {Type: "array", Items: &SchemaDef{Type: "uint64le"}},
+			"total":  {Type: "int32le"},
+		},
+		Order: []string{"id", "status", "ratio", "score", "items", "total"},
+	}
+	samples := []interface{}{
+		map[string]interface{}{"id": 1, "status": "active", "ratio": 0.5, "score": -100, "items": []interface{}{1, 2}, "total": 1500000},
+		map[string]interface{}{"id": 300, "status": "inactive", "ratio": 1.25, "score": 100, "items": []interface{}{3}, "total": 1500000},
+		[]byte(`{"id": 2, "status": "active", "ratio": 2, "score": 0, "items": [], "total": 1500000}`),
+		// can't be converted to JSON, ignored
+		map[string]interface{}{"id": func() {}},
+	}
+
+	optDef, suggestions := Optimize(schDef, samples)
+
+	expTypes := map[string]string{
+		"id":     "uint16be",
+		"status": "string",
+		"ratio":  "float32le",
+		"score":  "int8",
+		"total":  "int32le",
+	}
+	for name, typ := range expTypes {
+		if optDef.Properties[name].Type != typ {
+			t.Errorf("Optimize property '%s' type: %s, expect: %s", name, optDef.Properties[name].Type, typ)
+		}
+	}
+	if optDef.Properties["items"].Items.Type != "uint8" {
+		t.Errorf("Optimize items type: %s, expect: uint8", optDef.Properties["items"].Items.Type)
+	}
+
+	// def should not be modified
+	if schDef.Properties["id"].Type != "uint32be" || schDef.Properties["items"].Items.Type != "uint64le" {
+		t.Errorf("Optimize modified schema definition")
+	}
+
+	found := make(map[string]Suggestion)
+	for i, s := range suggestions {
+		if i > 0 && s.Savings > suggestions[i-1].Savings {
+			t.Errorf("suggestions are not sorted by savings")
+		}
+		found[s.Path+":"+string(s.Kind)] = s
+	}
+
+	if s, ok := found["items[]:narrow"]; !ok || !s.Applied || s.Savings != 21 || s.To != "uint8" {
+		t.Errorf("unexpected narrow suggestion of items[]: %+v", s)
+	}
+	if s, ok := found["id:narrow"]; !ok || s.Message != "values range from 1 to 300" {
+		t.Errorf("unexpected narrow suggestion of id: %+v", s)
+	}
+	if s, ok := found["ratio:float32"]; !ok || !s.Applied || s.Savings != 12 {
+		t.Errorf("unexpected float32 suggestion of ratio: %+v", s)
+	}
+	if s, ok := found["status:enum"]; !ok || s.Applied || s.Savings <= 0 {
+		t.Errorf("unexpected enum suggestion of status: %+v", s)
+	}
+	// values 1, 300 and 2 take 4 bytes in varint encoding and 6 bytes in uint16be
+	if s, ok := found["id:varint"]; !ok || s.Applied || s.From != "uint16be" || s.Savings != 2 {
+		t.Errorf("unexpected varint suggestion of id: %+v", s)
+	}
+	if s, ok := found["score:varint"]; ok {
+		t.Errorf("unexpected varint suggestion of score: %+v", s)
+	}
+	// 1500000 takes 3 bytes in unsigned varint, but 4 bytes in zigzag encoded signed varint
+	if s, ok := found["total:varint"]; ok {
+		t.Errorf("unexpected varint suggestion of total: %+v", s)
+	}
+
+	sch, err := NewJSONPack().AddSchema("optimized", *optDef)
+	if err != nil {
+		t.Fatalf("AddSchema with optimized schema definition fail, err: %+v", err)
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(samples[2].([]byte), &m); err != nil {
+		t.Fatalf("json.Unmarshal fail, err: %+v", err)
+	}
+	if _, err = sch.Encode(m); err != nil {
+		t.Errorf("Encode with optimized schema fail, err: %+v", err)
+	}
+}
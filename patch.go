@@ -0,0 +1,154 @@
+package jsonpack
+
+import (
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+Patch overwrites the value of property specified by path in encoded data directly,
+without decoding and re-encoding whole data.
+
+Only the property of fixed-width type, which is number or boolean type, can be patched in place,
+use PatchResize method to patch the property of variable-length type, likes string, array and object.
+
+The value can be any Go number type for number property, and it will be converted to
+the number type of property, the conversion fails if the value overflows the type of property.
+
+The path syntax is the same as Get method.
+
+It returns *PathError error if path is invalid or doesn't exist, or the property is not fixed-width type,
+and returns *EncodeError error if the value can't be converted to the type of property.
+
+Example:
+	// increases the counter
+	cnt, _ := sch.GetUint64(data, "stats.count")
+	err := sch.Patch(data, "stats.count", cnt+1)
+*/
+func (s *Schema) Patch(data []byte, path string, value interface{}) error {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return err
+	}
+	if opNode.handlerType != booleanOpType && !isNumberType(opNode.handlerType) {
+		return errors.WithStack(&PathError{s.Name, path,
+			errors.Errorf("%s type is not fixed-width, use PatchResize instead", opTypeName(opNode.handlerType))})
+	}
+
+	offset := buf.Offset()
+	if opNode.fixedSize > remainBytes(buf) {
+		return errors.WithStack(&PathError{s.Name, path, ibuf.BufferOverreadError})
+	}
+
+	// the buffer of property, writes value in place
+	err = writeBuiltinValue(ibuf.From(data[offset:offset+opNode.fixedSize]), opNode, value)
+	if err != nil {
+		return errors.WithStack(&EncodeError{s.Name, err})
+	}
+	return nil
+}
+
+/*
+PatchResize replaces the value of property specified by path in encoded data, the value
+can be any type which is supported by schema, includes variable-length types likes string,
+array and object.
+
+Only the bytes after the property will be moved when the encoded length of value changes, the
+data is modified in place when it has enough capacity, otherwise a new slice will be allocated.
+Always use the returned slice instead of data after patching.
+
+The value of array and object property is map[string]interface{} and []interface{} as
+Encode method.
+
+It returns *PathError error if path is invalid or doesn't exist, and returns *EncodeError
+error if the value can't be encoded with the type of property.
+
+Example:
+	data, err = sch.PatchResize(data, "user.name", "new name")
+*/
+func (s *Schema) PatchResize(data []byte, path string, value interface{}) ([]byte, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return nil, err
+	}
+
+	start := buf.Offset()
+	err = skipValue(buf, opNode)
+	if err != nil {
+		return nil, errors.WithStack(&PathError{s.Name, path, err})
+	}
+	end := buf.Offset()
+
+	raw, err := encodeValue(opNode, value)
+	if err != nil {
+		return nil, errors.WithStack(&EncodeError{s.Name, err})
+	}
+	return splice(data, start, end, raw), nil
+}
+
+// writeBuiltinValue writes value into buf with the type of number or boolean operation.
+func writeBuiltinValue(buf *ibuf.Buffer, opNode *operation, value interface{}) error {
+	if opNode.handlerType == booleanOpType {
+		val, ok := value.(bool)
+		if !ok {
+			return errors.WithStack(&TypeAssertionError{value, "bool"})
+		}
+		if val {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		return nil
+	}
+
+	n, ok := toNumber(value)
+	if !ok {
+		return errors.WithStack(&TypeAssertionError{value, opTypeName(opNode.handlerType)})
+	}
+	return writeNumber(buf, opNode.handlerType, n)
+}
+
+// encodeValue encodes value with operation opNode, and returns the encoded bytes.
+func encodeValue(opNode *operation, value interface{}) (raw []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case string:
+				err = errors.New(r)
+			case error:
+				err = r
+			}
+		}
+	}()
+
+	buf := ibuf.Create(0)
+	if opNode.handlerType == booleanOpType || isNumberType(opNode.handlerType) {
+		err = writeBuiltinValue(buf, opNode, value)
+	} else {
+		err = opNode.handler.encodeDynamic(buf, opNode, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Seal(), nil
+}
+
+// splice replaces data[start:end] with raw, it moves the bytes after end in place if
+// data has enough capacity, otherwise allocates a new slice.
+func splice(data []byte, start int64, end int64, raw []byte) []byte {
+	size := int64(len(data)) - (end - start) + int64(len(raw))
+	var result []byte
+	if size <= int64(cap(data)) {
+		result = data[:size]
+		copy(result[start+int64(len(raw)):], data[end:])
+	} else {
+		result = make([]byte, size)
+		copy(result, data[:start])
+		copy(result[start+int64(len(raw)):], data[end:])
+	}
+	copy(result[start:], raw)
+	return result
+}
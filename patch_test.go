@@ -0,0 +1,88 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestPatch(t *testing.T) {
+	var err error
+	var pathErr *PathError
+	var encodeErr *EncodeError
+
+	sch := jsonPack.GetSchema("complex")
+	data := append([]byte{}, testdata.ComplexExpData...)
+
+	err = sch.Patch(data, "category", 12345)
+	if err != nil {
+		t.Fatalf("Patch fail, err: %+v", err)
+	}
+	if val, _ := sch.GetUint64(data, "category"); val != 12345 {
+		t.Errorf("Patch category, expect: 12345, got: %d", val)
+	}
+	err = sch.Patch(data, "positions[1]", uint8(99))
+	if err != nil {
+		t.Fatalf("Patch fail, err: %+v", err)
+	}
+	if val, _ := sch.GetUint64(data, "positions[1]"); val != 99 {
+		t.Errorf("Patch positions[1], expect: 99, got: %d", val)
+	}
+	if len(data) != len(testdata.ComplexExpData) {
+		t.Errorf("Patch should not change length of data")
+	}
+
+	if err = sch.Patch(data, "positions[1]", 256); !errors.As(err, &encodeErr) {
+		t.Errorf("Patch overflow value should fail, err: %v", err)
+	}
+	if err = sch.Patch(data, "category", "1"); !errors.As(err, &encodeErr) {
+		t.Errorf("Patch wrong type value should fail, err: %v", err)
+	}
+	if err = sch.Patch(data, "user.name", "1"); !errors.As(err, &pathErr) {
+		t.Errorf("Patch variable-length property should fail, err: %v", err)
+	}
+	if err = sch.Patch(data, "positions[4]", 1); !errors.As(err, &pathErr) {
+		t.Errorf("Patch out of range item should fail, err: %v", err)
+	}
+
+	// variable-length values
+	data, err = sch.PatchResize(data, "user.name", "a much longer user name")
+	if err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+	data, err = sch.PatchResize(data, "ips", []interface{}{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+	data, err = sch.PatchResize(data, "accounts[1].currentStatus", map[string]interface{}{"group": "g", "msg": "m"})
+	if err != nil {
+		t.Fatalf("PatchResize fail, err: %+v", err)
+	}
+	if err = sch.Validate(data); err != nil {
+		t.Fatalf("Validate patched data fail, err: %+v", err)
+	}
+
+	expData := make(map[string]interface{})
+	if err = sch.Decode(testdata.ComplexExpData, &expData); err != nil {
+		t.Fatalf("Decode fail, err: %+v", err)
+	}
+	expData["category"] = uint32(12345)
+	expData["positions"].([]interface{})[1] = uint8(99)
+	expData["user"].(map[string]interface{})["name"] = "a much longer user name"
+	expData["ips"] = []interface{}{"10.0.0.1"}
+	expData["accounts"].([]interface{})[1].(map[string]interface{})["currentStatus"] = map[string]interface{}{"group": "g", "msg": "m"}
+
+	patchedData := make(map[string]interface{})
+	if err = sch.Decode(data, &patchedData); err != nil {
+		t.Fatalf("Decode patched data fail, err: %+v", err)
+	}
+	if !compareMap(expData, patchedData) {
+		t.Errorf("PatchResize, expect: %v, got: %v", expData, patchedData)
+	}
+
+	if _, err = sch.PatchResize(data, "user.name", 1); !errors.As(err, &encodeErr) {
+		t.Errorf("PatchResize wrong type value should fail, err: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package protoconv
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/arloliu/jsonpack"
+)
+
+/*
+AddSchema adds a new schema to p which schema definition is converted from the descriptor of
+protobuf message m by FromDescriptor function with default options.
+
+The message can be encoded directly with the returned schema by Encode function.
+
+Example:
+	sch, err := protoconv.AddSchema(jsonPack, "Info", &pb.Info{})
+	data, err := protoconv.Encode(sch, &pb.Info{Name: "example name"})
+*/
+func AddSchema(p *jsonpack.JSONPack, schemaName string, m proto.Message) (*jsonpack.Schema, error) {
+	schDef, err := FromDescriptor(m.ProtoReflect().Descriptor(), Options{})
+	if err != nil {
+		return nil, err
+	}
+	return p.AddSchema(schemaName, *schDef)
+}
+
+/*
+Encode encodes protobuf message m with schema sch, the schema is usually added by AddSchema
+function or converted by FromDescriptor function.
+
+The fields of m are matched with properties by name, the properties which have no matched
+field are encoded as zero values, and the values of fields are converted to the types of
+properties, likes int32 field to int16 property if the value fits.
+
+The schema definition of sch is parsed at the first call and cached, so the schema is expected to be
+long-lived, likes the schemas added at startup.
+
+It returns *jsonpack.EncodeError error if the types of field and property mismatch, or the value of
+field can't be represented by the type of property.
+*/
+func Encode(sch *jsonpack.Schema, m proto.Message) ([]byte, error) {
+	schDef, err := schemaDef(sch)
+	if err != nil {
+		return nil, errors.WithStack(&jsonpack.EncodeError{Name: sch.Name, Err: err})
+	}
+
+	v, err := messageValue(schDef, m.ProtoReflect())
+	if err != nil {
+		return nil, errors.WithStack(&jsonpack.EncodeError{Name: sch.Name, Err: err})
+	}
+	return sch.Encode(v)
+}
+
+// schemaDefs caches the schema definitions of schemas, the key is *jsonpack.Schema and the value is
+// *jsonpack.SchemaDef, so the schema definition isn't parsed for every message.
+var schemaDefs sync.Map
+
+// schemaDef returns the cached schema definition of sch.
+func schemaDef(sch *jsonpack.Schema) (*jsonpack.SchemaDef, error) {
+	if def, ok := schemaDefs.Load(sch); ok {
+		return def.(*jsonpack.SchemaDef), nil
+	}
+	def, err := sch.GetSchemaDef()
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(def.Type) != "object" {
+		return nil, errors.New("type of top-level schema definition needs to be object")
+	}
+	schemaDefs.Store(sch, def)
+	return def, nil
+}
+
+// messageValue converts msg to the map which has property names and value types of object
+// schema definition def.
+func messageValue(def *jsonpack.SchemaDef, msg protoreflect.Message) (map[string]interface{}, error) {
+	fields := msg.Descriptor().Fields()
+	obj := make(map[string]interface{}, len(def.Order))
+	for _, propName := range def.Order {
+		propDef := def.Properties[propName]
+		fd := fields.ByName(protoreflect.Name(propName))
+		if fd == nil {
+			v, err := zeroValue(propDef)
+			if err != nil {
+				return nil, errors.Wrapf(err, "property '%s'", propName)
+			}
+			obj[propName] = v
+			continue
+		}
+
+		v, err := fieldValue(propDef, fd, msg.Get(fd))
+		if err != nil {
+			return nil, errors.Wrapf(err, "field '%s'", fd.FullName())
+		}
+		obj[propName] = v
+	}
+	return obj, nil
+}
+
+func fieldValue(def *jsonpack.SchemaDef, fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	if fd.IsMap() {
+		return nil, errors.New("map field is not supported")
+	}
+	if !fd.IsList() {
+		return itemValue(def, fd, v)
+	}
+
+	if strings.ToLower(def.Type) != "array" {
+		return nil, errors.WithStack(&jsonpack.TypeAssertionError{Data: v.Interface(), ExpectedType: def.Type})
+	}
+	list := v.List()
+	items := make([]interface{}, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item, err := itemValue(def.Items, fd, list.Get(i))
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func itemValue(def *jsonpack.SchemaDef, fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	typ := strings.ToLower(def.Type)
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if typ == "object" {
+			return messageValue(def, v.Message())
+		}
+
+	case protoreflect.BytesKind:
+		if typ == "array" && def.Items != nil {
+			data := v.Bytes()
+			items := make([]interface{}, len(data))
+			for i, b := range data {
+				item, err := jsonpack.ConvertNumber(def.Items.Type, uint64(b))
+				if err != nil {
+					return nil, err
+				}
+				items[i] = item
+			}
+			return items, nil
+		}
+
+	case protoreflect.StringKind:
+		if typ == "string" {
+			return v.String(), nil
+		}
+
+	case protoreflect.BoolKind:
+		if typ, _ = jsonpack.CanonicalType(typ); typ == "boolean" {
+			return v.Bool(), nil
+		}
+
+	case protoreflect.EnumKind:
+		return jsonpack.ConvertNumber(def.Type, int64(v.Enum()))
+
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return jsonpack.ConvertNumber(def.Type, v.Int())
+
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return jsonpack.ConvertNumber(def.Type, v.Uint())
+
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return jsonpack.ConvertNumber(def.Type, v.Float())
+	}
+	return nil, errors.WithStack(&jsonpack.TypeAssertionError{Data: v.Interface(), ExpectedType: def.Type})
+}
+
+// zeroValue returns zero value of schema definition def.
+func zeroValue(def *jsonpack.SchemaDef) (interface{}, error) {
+	switch typ := strings.ToLower(def.Type); typ {
+	case "object":
+		obj := make(map[string]interface{}, len(def.Order))
+		for _, propName := range def.Order {
+			v, err := zeroValue(def.Properties[propName])
+			if err != nil {
+				return nil, err
+			}
+			obj[propName] = v
+		}
+		return obj, nil
+	case "array":
+		return []interface{}{}, nil
+	case "string":
+		return "", nil
+	}
+	if typ, _ := jsonpack.CanonicalType(def.Type); typ == "boolean" {
+		return false, nil
+	}
+	return jsonpack.ConvertNumber(def.Type, int64(0))
+}
@@ -0,0 +1,405 @@
+// Package protoconv converts between protobuf message descriptors and jsonpack schema definitions,
+// and encodes protobuf messages with jsonpack schemas.
+//
+// It's a separate package so that the users of jsonpack which don't use protobuf don't depend on it.
+package protoconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/arloliu/jsonpack"
+)
+
+// Options represents the options of FromDescriptor function.
+type Options struct {
+	// rule of ordering properties, jsonpack.DocumentOrder orders properties by the order of field declarations
+	Order jsonpack.PropertyOrder
+	// use big-endian number types instead of little-endian types
+	BigEndian bool
+}
+
+/*
+FromDescriptor converts protobuf message descriptor md to schema definition.
+
+The fields of message are converted to properties with the same names, and the types are converted as:
+
+* bool to boolean type, string to string type, and bytes to array of uint8 type.
+
+* int32, sint32, sfixed32 and enum to int32 type, int64, sint64 and sfixed64 to int64 type,
+uint32 and fixed32 to uint32 type, uint64 and fixed64 to uint64 type.
+
+* float to float32 type, double to float64 type.
+
+* message to object type, and repeated field to array type.
+
+The fields which have presence, likes message fields and proto3 optional fields, are marked as optional.
+
+It returns *jsonpack.ConvertError error with the full name of field if md has map fields or recursive messages,
+which can't be represented by schema definition.
+
+Example:
+	schDef, err := protoconv.FromDescriptor((&pb.Info{}).ProtoReflect().Descriptor(), protoconv.Options{})
+*/
+func FromDescriptor(md protoreflect.MessageDescriptor, opts Options) (*jsonpack.SchemaDef, error) {
+	c := converter{opts: opts, resolving: make(map[protoreflect.FullName]bool)}
+	return c.messageDef(md)
+}
+
+// converter converts protobuf message descriptor to schema definition.
+type converter struct {
+	opts Options
+	// messages which are being converted, for detecting recursive messages
+	resolving map[protoreflect.FullName]bool
+}
+
+func (c *converter) messageDef(md protoreflect.MessageDescriptor) (*jsonpack.SchemaDef, error) {
+	if c.resolving[md.FullName()] {
+		return nil, convertError(string(md.FullName()), errors.New("recursive message is not supported"))
+	}
+	c.resolving[md.FullName()] = true
+	defer delete(c.resolving, md.FullName())
+
+	fields := md.Fields()
+	schDef := &jsonpack.SchemaDef{
+		Type:       "object",
+		Properties: make(map[string]*jsonpack.SchemaDef, fields.Len()),
+		Order:      make([]string, 0, fields.Len()),
+	}
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		propDef, err := c.fieldDef(fd)
+		if err != nil {
+			return nil, err
+		}
+		propDef.Optional = fd.HasPresence()
+
+		name := string(fd.Name())
+		schDef.Properties[name] = propDef
+		schDef.Order = append(schDef.Order, name)
+	}
+	if c.opts.Order == jsonpack.SortedOrder {
+		sort.Strings(schDef.Order)
+	}
+	return schDef, nil
+}
+
+func (c *converter) fieldDef(fd protoreflect.FieldDescriptor) (*jsonpack.SchemaDef, error) {
+	if fd.IsMap() {
+		return nil, convertError(string(fd.FullName()), errors.New("map field is not supported"))
+	}
+
+	var itemDef *jsonpack.SchemaDef
+	suffix := "le"
+	if c.opts.BigEndian {
+		suffix = "be"
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		itemDef = &jsonpack.SchemaDef{Type: "boolean"}
+	case protoreflect.StringKind:
+		itemDef = &jsonpack.SchemaDef{Type: "string"}
+	case protoreflect.BytesKind:
+		itemDef = &jsonpack.SchemaDef{Type: "array", Items: &jsonpack.SchemaDef{Type: "uint8"}}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind, protoreflect.EnumKind:
+		itemDef = &jsonpack.SchemaDef{Type: "int32" + suffix}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		itemDef = &jsonpack.SchemaDef{Type: "int64" + suffix}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		itemDef = &jsonpack.SchemaDef{Type: "uint32" + suffix}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		itemDef = &jsonpack.SchemaDef{Type: "uint64" + suffix}
+	case protoreflect.FloatKind:
+		itemDef = &jsonpack.SchemaDef{Type: "float32" + suffix}
+	case protoreflect.DoubleKind:
+		itemDef = &jsonpack.SchemaDef{Type: "float64" + suffix}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		var err error
+		itemDef, err = c.messageDef(fd.Message())
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, convertError(string(fd.FullName()), errors.Errorf("kind '%s' is not supported", fd.Kind()))
+	}
+
+	if fd.IsList() {
+		return &jsonpack.SchemaDef{Type: "array", Items: itemDef}, nil
+	}
+	return itemDef, nil
+}
+
+/*
+ToDescriptor converts schema definition def to protobuf message descriptor of proto3 syntax,
+fullName is the full name of message likes "example.Info".
+
+The properties are converted to fields numbered in the order of schema definition, the object
+properties are converted to nested messages named by the upper camel case of property names,
+and the optional properties of number, boolean and string types are converted to proto3
+optional fields.
+
+The number types are converted to the smallest protobuf types that hold them, likes uint8 and
+uint16 types to uint32, and the array of uint8 type is converted to bytes.
+
+It returns *jsonpack.ConvertError error if the top-level type of def isn't object, the property name
+isn't a valid protobuf identifier, or def has array of arrays which can't be represented by protobuf.
+*/
+func ToDescriptor(def *jsonpack.SchemaDef, fullName string) (protoreflect.MessageDescriptor, error) {
+	pkg, name := "", fullName
+	if idx := strings.LastIndexByte(fullName, '.'); idx >= 0 {
+		pkg, name = fullName[:idx], fullName[idx+1:]
+	}
+	if def == nil || strings.ToLower(def.Type) != "object" {
+		return nil, convertError("", errors.New("type of top-level schema definition needs to be object"))
+	}
+	if !isProtoIdent(name) {
+		return nil, convertError("", errors.Errorf("invalid message name '%s'", fullName))
+	}
+
+	fileName := name + ".proto"
+	if pkg != "" {
+		fileName = strings.ReplaceAll(pkg, ".", "/") + "/" + fileName
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String(fileName),
+		Syntax: proto.String("proto3"),
+	}
+	if pkg != "" {
+		fdp.Package = proto.String(pkg)
+	}
+
+	msg, err := messageProto(def, name, "."+fullName, "")
+	if err != nil {
+		return nil, err
+	}
+	fdp.MessageType = []*descriptorpb.DescriptorProto{msg}
+
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		return nil, convertError("", err)
+	}
+	return fd.Messages().ByName(protoreflect.Name(name)), nil
+}
+
+// messageProto returns message descriptor of object schema definition def, typeName is the fully-qualified
+// name of message, and path is the property path of def.
+func messageProto(def *jsonpack.SchemaDef, name string, typeName string, path string) (*descriptorpb.DescriptorProto, error) {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	nestedNames := make(map[string]bool)
+	optionalFields := make([]*descriptorpb.FieldDescriptorProto, 0)
+
+	for i, propName := range def.Order {
+		propPath := propertyPath(path, propName)
+		prop, ok := def.Properties[propName]
+		if !ok || prop == nil {
+			return nil, convertError(propPath, errors.New("property in order doesn't exist"))
+		}
+		if !isProtoIdent(propName) {
+			return nil, convertError(propPath, errors.New("property name isn't a valid protobuf identifier"))
+		}
+
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(propName),
+			Number: proto.Int32(int32(i + 1)),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+
+		itemDef := prop
+		typ := strings.ToLower(prop.Type)
+		if typ == "array" {
+			itemDef = prop.Items
+			if itemDef == nil {
+				return nil, convertError(propPath, errors.New("items of array is not specified"))
+			}
+			itemTyp := strings.ToLower(itemDef.Type)
+			if itemTyp == "uint8" {
+				field.Type = descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()
+				msg.Field = append(msg.Field, field)
+				continue
+			}
+			if itemTyp == "array" {
+				return nil, convertError(propPath, errors.New("array of arrays is not supported"))
+			}
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			typ = itemTyp
+		}
+
+		if typ == "object" {
+			nestedName := protoMessageName(propName)
+			for nestedNames[nestedName] {
+				nestedName += "_"
+			}
+			nestedNames[nestedName] = true
+
+			nested, err := messageProto(itemDef, nestedName, typeName+"."+nestedName, propPath)
+			if err != nil {
+				return nil, err
+			}
+			msg.NestedType = append(msg.NestedType, nested)
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String(typeName + "." + nestedName)
+			msg.Field = append(msg.Field, field)
+			continue
+		}
+
+		builtinType, ok := jsonpack.CanonicalType(typ)
+		if !ok {
+			return nil, convertError(propPath, &jsonpack.UnknownTypeError{DataType: itemDef.Type})
+		}
+		field.Type = protoFieldType(builtinType).Enum()
+		if prop.Optional && field.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+			field.Proto3Optional = proto.Bool(true)
+			optionalFields = append(optionalFields, field)
+		}
+		msg.Field = append(msg.Field, field)
+	}
+
+	// proto3 optional fields belong to synthetic oneofs
+	for _, field := range optionalFields {
+		field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+		msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.GetName())})
+	}
+	return msg, nil
+}
+
+// protoFieldType returns protobuf field type of canonical builtin type.
+func protoFieldType(typ string) descriptorpb.FieldDescriptorProto_Type {
+	switch typ {
+	case "boolean":
+		return descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	case "string":
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING
+	case "int8", "int16le", "int16be", "int32le", "int32be":
+		return descriptorpb.FieldDescriptorProto_TYPE_INT32
+	case "int64le", "int64be":
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case "uint8", "uint16le", "uint16be", "uint32le", "uint32be":
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT32
+	case "uint64le", "uint64be":
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT64
+	case "float32le", "float32be":
+		return descriptorpb.FieldDescriptorProto_TYPE_FLOAT
+	}
+	return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+}
+
+// protoMessageName returns upper camel case of property name, likes "CurrentStatus" for "current_status".
+func protoMessageName(propName string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range propName {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "Message"
+	}
+	return sb.String()
+}
+
+// isProtoIdent reports whether s is a valid protobuf identifier.
+func isProtoIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ToText converts schema definition def to the text of .proto file, fullName is the full name of
+message likes "example.Info", the conversion rules are the same as ToDescriptor function.
+
+Example output of ToText(schDef, "example.Info"):
+	syntax = "proto3";
+
+	package example;
+
+	message Info {
+	  string name = 1;
+	  optional uint32 area = 2;
+	  repeated string tags = 3;
+	  Address address = 4;
+
+	  message Address {
+	    string street = 1;
+	  }
+	}
+*/
+func ToText(def *jsonpack.SchemaDef, fullName string) ([]byte, error) {
+	md, err := ToDescriptor(def, fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	if pkg := md.ParentFile().Package(); pkg != "" {
+		fmt.Fprintf(&sb, "package %s;\n\n", pkg)
+	}
+	writeProtoMessage(&sb, md, "")
+	return []byte(sb.String()), nil
+}
+
+// writeProtoMessage writes message declaration of md with its nested messages to sb.
+func writeProtoMessage(sb *strings.Builder, md protoreflect.MessageDescriptor, indent string) {
+	fmt.Fprintf(sb, "%smessage %s {\n", indent, md.Name())
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		sb.WriteString(indent + "  ")
+		if fd.IsList() {
+			sb.WriteString("repeated ")
+		} else if fd.HasOptionalKeyword() {
+			sb.WriteString("optional ")
+		}
+		if fd.Kind() == protoreflect.MessageKind {
+			sb.WriteString(string(fd.Message().Name()))
+		} else {
+			sb.WriteString(fd.Kind().String())
+		}
+		fmt.Fprintf(sb, " %s = %d;\n", fd.Name(), fd.Number())
+	}
+
+	messages := md.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		sb.WriteString("\n")
+		writeProtoMessage(sb, messages.Get(i), indent+"  ")
+	}
+	fmt.Fprintf(sb, "%s}\n", indent)
+}
+
+// propertyPath returns the property path of propName in the object of path.
+func propertyPath(path string, propName string) string {
+	if path == "" {
+		return propName
+	}
+	return path + "." + propName
+}
+
+func convertError(path string, err error) error {
+	return errors.WithStack(&jsonpack.ConvertError{Path: path, Err: err})
+}
@@ -0,0 +1,245 @@
+package protoconv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/arloliu/jsonpack"
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestFromDescriptor(t *testing.T) {
+	md := (&testdata.ComplextPb{}).ProtoReflect().Descriptor()
+	schDef, err := FromDescriptor(md, Options{})
+	if err != nil {
+		t.Fatalf("FromDescriptor fail, err: %+v", err)
+	}
+
+	userDef := newUserDef()
+	expDef := &jsonpack.SchemaDef{
+		Type: "object",
+		Properties: map[string]*jsonpack.SchemaDef{
+			"category":  {Type: "uint32le"},
+			"ips":       {Type: "array", Items: &jsonpack.SchemaDef{Type: "string"}},
+			"positions": {Type: "array", Items: &jsonpack.SchemaDef{Type: "uint32le"}},
+			"user":      newUserDef(),
+			"accounts":  {Type: "array", Items: userDef},
+		},
+		Order: []string{"category", "ips", "positions", "user", "accounts"},
+	}
+	expDef.Properties["user"].Optional = true
+	if !reflect.DeepEqual(schDef, expDef) {
+		t.Errorf("FromDescriptor result: %+v, expect: %+v", schDef, expDef)
+	}
+
+	schDef, err = FromDescriptor(md, Options{Order: jsonpack.SortedOrder, BigEndian: true})
+	if err != nil {
+		t.Fatalf("FromDescriptor fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(schDef.Order, []string{"accounts", "category", "ips", "positions", "user"}) || schDef.Properties["category"].Type != "uint32be" {
+		t.Errorf("FromDescriptor with options result: %+v", schDef)
+	}
+}
+
+// newUserDef returns schema definition of ComplexUserPb message.
+func newUserDef() *jsonpack.SchemaDef {
+	return &jsonpack.SchemaDef{
+		Type: "object",
+		Properties: map[string]*jsonpack.SchemaDef{
+			"name":  {Type: "string"},
+			"email": {Type: "string"},
+			"currentStatus": {
+				Type: "object",
+				Properties: map[string]*jsonpack.SchemaDef{
+					"group": {Type: "string"},
+					"msg":   {Type: "string"},
+				},
+				Order:    []string{"group", "msg"},
+				Optional: true,
+			},
+		},
+		Order: []string{"name", "email", "currentStatus"},
+	}
+}
+
+func TestEncode(t *testing.T) {
+	sch, err := AddSchema(jsonpack.NewJSONPack(), "complexPb", &testdata.ComplextPb{})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	msg := &testdata.ComplextPb{
+		Category:  3,
+		Ips:       []string{"10.0.0.1", "10.0.0.2"},
+		Positions: []uint32{1, 300},
+		User:      &testdata.ComplexUserPb{Name: "user", CurrentStatus: &testdata.ComplextStatusPb{Group: "g", Msg: "m"}},
+		Accounts:  []*testdata.ComplexUserPb{{Name: "a1", Email: "a1@example.com"}},
+	}
+	data, err := Encode(sch, msg)
+	if err != nil {
+		t.Fatalf("Encode fail, err: %+v", err)
+	}
+
+	result := make(map[string]interface{})
+	err = sch.Decode(data, &result)
+	if err != nil {
+		t.Fatalf("Decode fail, err: %+v", err)
+	}
+	expResult := map[string]interface{}{
+		"category":  uint32(3),
+		"ips":       []interface{}{"10.0.0.1", "10.0.0.2"},
+		"positions": []interface{}{uint32(1), uint32(300)},
+		"user": map[string]interface{}{
+			"name":          "user",
+			"email":         "",
+			"currentStatus": map[string]interface{}{"group": "g", "msg": "m"},
+		},
+		"accounts": []interface{}{
+			map[string]interface{}{
+				"name":          "a1",
+				"email":         "a1@example.com",
+				"currentStatus": map[string]interface{}{"group": "", "msg": ""},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expResult) {
+		t.Errorf("Encode decoded result: %+v, expect: %+v", result, expResult)
+	}
+
+	// the value of field is converted to the type of property
+	narrowDef, _ := sch.GetSchemaDef()
+	narrowDef.Properties["positions"].Items.Type = "uint8"
+	narrowSch, err := jsonpack.NewJSONPack().AddSchema("narrow", *narrowDef)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	var encodeErr *jsonpack.EncodeError
+	if _, err = Encode(narrowSch, msg); !errors.As(err, &encodeErr) {
+		t.Errorf("Encode with overflow value should fail, err: %v", err)
+	}
+	msg.Positions = []uint32{1, 255}
+	if _, err = Encode(narrowSch, msg); err != nil {
+		t.Errorf("Encode with narrow type fail, err: %+v", err)
+	}
+
+	// the types of field and property mismatch
+	mismatchDef, _ := sch.GetSchemaDef()
+	mismatchDef.Properties["ips"].Items.Type = "int8"
+	mismatchSch, err := jsonpack.NewJSONPack().AddSchema("mismatch", *mismatchDef)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	if _, err = Encode(mismatchSch, msg); !errors.As(err, &encodeErr) {
+		t.Errorf("Encode with mismatched type should fail, err: %v", err)
+	}
+}
+
+func TestToDescriptor(t *testing.T) {
+	schDef := &jsonpack.SchemaDef{
+		Type: "object",
+		Properties: map[string]*jsonpack.SchemaDef{
+			"name":  {Type: "string"},
+			"area":  {Type: "uint16le", Optional: true},
+			"score": {Type: "int8"},
+			"ratio": {Type: "float64be"},
+			"tags":  {Type: "array", Items: &jsonpack.SchemaDef{Type: "string"}},
+			"raw":   {Type: "array", Items: &jsonpack.SchemaDef{Type: "uint8"}},
+			"current_status": {
+				Type: "object",
+				Properties: map[string]*jsonpack.SchemaDef{
+					"code": {Type: "int64le"},
+				},
+				Order: []string{"code"},
+			},
+			"history": {
+				Type: "array",
+				Items: &jsonpack.SchemaDef{
+					Type: "object",
+					Properties: map[string]*jsonpack.SchemaDef{
+						"ok": {Type: "boolean"},
+					},
+					Order: []string{"ok"},
+				},
+			},
+		},
+		Order: []string{"name", "area", "score", "ratio", "tags", "raw", "current_status", "history"},
+	}
+
+	md, err := ToDescriptor(schDef, "example.Info")
+	if err != nil {
+		t.Fatalf("ToDescriptor fail, err: %+v", err)
+	}
+	if md.FullName() != "example.Info" {
+		t.Errorf("ToDescriptor full name: %s, expect: example.Info", md.FullName())
+	}
+	expKinds := []protoreflect.Kind{
+		protoreflect.StringKind, protoreflect.Uint32Kind, protoreflect.Int32Kind, protoreflect.DoubleKind,
+		protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.MessageKind,
+	}
+	for i, kind := range expKinds {
+		if fd := md.Fields().Get(i); fd.Kind() != kind || fd.Number() != protoreflect.FieldNumber(i+1) {
+			t.Errorf("ToDescriptor field %s: kind %s number %d, expect: %s %d", fd.Name(), fd.Kind(), fd.Number(), kind, i+1)
+		}
+	}
+	if fd := md.Fields().ByName("history"); !fd.IsList() || fd.Message().FullName() != "example.Info.History" {
+		t.Errorf("ToDescriptor history field: %v", fd)
+	}
+
+	text, err := ToText(schDef, "example.Info")
+	if err != nil {
+		t.Fatalf("ToText fail, err: %+v", err)
+	}
+	expText := `syntax = "proto3";
+
+package example;
+
+message Info {
+  string name = 1;
+  optional uint32 area = 2;
+  int32 score = 3;
+  double ratio = 4;
+  repeated string tags = 5;
+  bytes raw = 6;
+  CurrentStatus current_status = 7;
+  repeated History history = 8;
+
+  message CurrentStatus {
+    int64 code = 1;
+  }
+
+  message History {
+    bool ok = 1;
+  }
+}
+`
+	if string(text) != expText {
+		t.Errorf("ToText result:\n%s\nexpect:\n%s", text, expText)
+	}
+
+	// the converted descriptor can be converted back
+	backDef, err := FromDescriptor(md, Options{})
+	if err != nil {
+		t.Fatalf("FromDescriptor fail, err: %+v", err)
+	}
+	if backDef.Properties["raw"].Items.Type != "uint8" || !backDef.Properties["area"].Optional {
+		t.Errorf("FromDescriptor of converted descriptor result: %+v", backDef)
+	}
+
+	invalidDefs := map[string]*jsonpack.SchemaDef{
+		"":         {Type: "array", Items: &jsonpack.SchemaDef{Type: "string"}},
+		"bad-name": {Type: "object", Properties: map[string]*jsonpack.SchemaDef{"bad-name": {Type: "string"}}, Order: []string{"bad-name"}},
+		"matrix": {Type: "object", Properties: map[string]*jsonpack.SchemaDef{
+			"matrix": {Type: "array", Items: &jsonpack.SchemaDef{Type: "array", Items: &jsonpack.SchemaDef{Type: "int8"}}},
+		}, Order: []string{"matrix"}},
+	}
+	for path, def := range invalidDefs {
+		var convertErr *jsonpack.ConvertError
+		_, err = ToDescriptor(def, "example.Invalid")
+		if !errors.As(err, &convertErr) || convertErr.Path != path {
+			t.Errorf("ToDescriptor should fail at '%s', err: %v", path, err)
+		}
+	}
+}
@@ -25,14 +25,20 @@ const (
 type Schema struct {
 	// schema name
 	Name string
+	// schema version, the schema added by AddSchema will be version 1 if it doesn't exist before.
+	Version uint32
 	// rawData stores schema definition from user, can be map, struct, string or slice of byte
 	rawData interface{}
 	// textData stores text json format of schema definition
-	textData      []byte
+	textData []byte
+	// fingerprint is the result of SchemaFingerprint of schema definition
+	fingerprint   string
 	rootOp        *operation
 	structOpCache *sync.Map
-	encodeBufSize int64
-	byteOrder     ByteOrder
+	// projectionOpCache stores struct operations for DecodeProjection method
+	projectionOpCache *sync.Map
+	encodeBufSize     int64
+	byteOrder         ByteOrder
 }
 
 // SchemaDef represents a schema definition that defines the structure of JSON document.
@@ -47,10 +53,13 @@ type Schema struct {
 //		Order: []string{"name", "area"},
 //	}
 type SchemaDef struct {
-	Type       string                `json:"type"`
-	Properties map[string]*SchemaDef `json:"properties,omitempty"`
-	Items      *SchemaDef            `json:"items,omitempty"`
-	Order      []string              `json:"order,omitempty"`
+	Type       string                `json:"type" yaml:"type"`
+	Properties map[string]*SchemaDef `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Items      *SchemaDef            `json:"items,omitempty" yaml:"items,omitempty"`
+	Order      []string              `json:"order,omitempty" yaml:"order,omitempty"`
+	// Optional marks the property may be missing or null in JSON documents, it's informational
+	// and doesn't change encoding, the property is always encoded.
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
 }
 
 // GetSchemaDef returns a schema definition instance,
@@ -69,6 +78,62 @@ func (s *Schema) GetSchemaDefText() []byte {
 	return s.textData
 }
 
+// CanonicalType returns canonical name of builtin type typ, likes "float64le" for "doubleLE" and
+// "boolean" for "bool", the ok is false if typ isn't a builtin type.
+func CanonicalType(typ string) (string, bool) {
+	opType, ok := builtinOpHandlerTypes[strings.ToLower(typ)]
+	if !ok {
+		return "", false
+	}
+	return opTypeName(opType), true
+}
+
+// canonicalSchemaDef returns the schema definition which has the same encoding format as def,
+// the type names are canonical and the properties which don't exist in order are omitted,
+// it's also the schema of Buffer Plus.
+//
+// The def must be a valid schema definition.
+func canonicalSchemaDef(def *SchemaDef) *SchemaDef {
+	if def == nil {
+		return nil
+	}
+	schema := &SchemaDef{}
+	switch typ := strings.ToLower(def.Type); typ {
+	case "object":
+		schema.Type = typ
+		schema.Properties = make(map[string]*SchemaDef, len(def.Order))
+		schema.Order = append([]string{}, def.Order...)
+		for _, propName := range def.Order {
+			schema.Properties[propName] = canonicalSchemaDef(def.Properties[propName])
+		}
+	case "array":
+		schema.Type = typ
+		schema.Items = canonicalSchemaDef(def.Items)
+	default:
+		schema.Type = opTypeName(builtinOpHandlerTypes[typ])
+	}
+	return schema
+}
+
+// cloneSchemaDef returns a deep copy of schema definition.
+func cloneSchemaDef(schDef *SchemaDef) *SchemaDef {
+	if schDef == nil {
+		return nil
+	}
+	newDef := *schDef
+	if schDef.Properties != nil {
+		newDef.Properties = make(map[string]*SchemaDef, len(schDef.Properties))
+		for name, prop := range schDef.Properties {
+			newDef.Properties[name] = cloneSchemaDef(prop)
+		}
+	}
+	if schDef.Order != nil {
+		newDef.Order = append([]string{}, schDef.Order...)
+	}
+	newDef.Items = cloneSchemaDef(schDef.Items)
+	return &newDef
+}
+
 // newSchema returns a schema instance
 func newSchema(name string, v ...interface{}) *Schema {
 	var rawData interface{}
@@ -81,13 +146,14 @@ func newSchema(name string, v ...interface{}) *Schema {
 	}
 
 	instance := Schema{
-		Name:          name,
-		rawData:       rawData,
-		textData:      nil,
-		rootOp:        newOperation("", &nullOp{}, nullOpType),
-		structOpCache: &sync.Map{},
-		encodeBufSize: 512,
-		byteOrder:     byteOrder,
+		Name:              name,
+		rawData:           rawData,
+		textData:          nil,
+		rootOp:            newOperation("", &nullOp{}, nullOpType),
+		structOpCache:     &sync.Map{},
+		projectionOpCache: &sync.Map{},
+		encodeBufSize:     512,
+		byteOrder:         byteOrder,
 	}
 	return &instance
 }
@@ -260,12 +326,20 @@ func (s *Schema) buildFromMap(schema map[string]interface{}) error {
 	default:
 		return errors.New("type property needs to be 'object' or 'array' in top-level schema definition")
 	}
+	s.rootOp.updateFixedSize()
+
 	// parse schema map object into JSON encoded text data
 	s.textData, err = json.Marshal(schema)
 	if err != nil {
 		return err
 	}
 
+	schDef, err := s.GetSchemaDef()
+	if err != nil {
+		return err
+	}
+	s.fingerprint = SchemaFingerprint(schDef)
+
 	return nil
 }
 
@@ -288,7 +362,11 @@ func _getOrder(order interface{}) []string {
 	case []interface{}:
 		data := make([]string, len(order))
 		for i, v := range order {
-			data[i] = v.(string)
+			name, ok := v.(string)
+			if !ok {
+				return nil
+			}
+			data[i] = name
 		}
 		return data
 
@@ -300,6 +378,14 @@ func _getOrder(order interface{}) []string {
 
 func (s *Schema) compileSchemaObject(schema map[string]interface{}, curOp *operation) error {
 	var err error
+	// the empty object may omit both 'properties' and 'order' property, likes the
+	// definition marshalled from SchemaDef which omits the empty fields
+	_, hasProperties := schema["properties"]
+	_, hasOrder := schema["order"]
+	if !hasProperties && !hasOrder {
+		return nil
+	}
+
 	err = checkObjectProperties(schema)
 	if err != nil {
 		return err
@@ -317,7 +403,10 @@ func (s *Schema) compileSchemaObject(schema map[string]interface{}, curOp *opera
 	}
 
 	for _, fieldName := range order {
-		prop := properties[fieldName].(map[string]interface{})
+		prop, ok := properties[fieldName].(map[string]interface{})
+		if !ok {
+			return errors.Errorf("'%s' in 'order' property doesn't exist in 'properties' property", fieldName)
+		}
 		propType, ok := prop["type"].(string)
 		if !ok {
 			return errors.New("Object type of schema definition requires valid 'type' field")
@@ -395,7 +484,8 @@ func (s *Schema) getTypeFromKind(kind reflect.Kind) string {
 		}
 		return s.getTypeEndian("int64")
 	case reflect.Int8:
-		return "uint8"
+		// it was "uint8" in previous versions, the encoded data is the same but decoded values differ
+		return "int8"
 	case reflect.Int16:
 		return s.getTypeEndian("int16")
 	case reflect.Int32:
@@ -29,7 +29,7 @@ Example of decoding data into Info struct instance with "Info" schema
 	err := jsonPack.Decode("Info", encodedData, &decodeInfoStruct)
 */
 func (s *Schema) Decode(data []byte, v interface{}) (err error) {
-	return s.decode(data, v, true)
+	return s.decode(data, v, true, false)
 }
 
 // Unmarshal is an alias to Decode function, provides familiar interface of json package
@@ -37,7 +37,29 @@ func (s *Schema) Unmarshal(data []byte, v interface{}) (err error) {
 	return s.Decode(data, v)
 }
 
-func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err error) {
+/*
+DecodeProjection reads encoded data with compiled schema definition and stores the result
+in the struct pointed to v, the struct only needs to declare the fields it cares about.
+
+Unlike Decode method, which returns StructFieldNonExistError error if struct lacks
+any property of schema, DecodeProjection skips the encoded values of properties
+that don't exist in struct without decoding them, and stops reading once the last
+declared field of the struct has been decoded.
+
+Decoding into map is the same as Decode method, all properties will be decoded.
+
+Example of decoding the "name" property only with "Info" schema
+	type InfoName struct {
+		Name string `json:"name"`
+	}
+	infoName := InfoName{}
+	err := jsonPack.DecodeProjection("Info", encodedData, &infoName)
+*/
+func (s *Schema) DecodeProjection(data []byte, v interface{}) (err error) {
+	return s.decode(data, v, true, true)
+}
+
+func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool, projection bool) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			switch r := r.(type) {
@@ -70,6 +92,15 @@ func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err erro
 		return
 	}
 
+	// Unmarshaler decodes all properties, the projection decoding always uses schema definition
+	if d, ok := s.unmarshalerOf(v); ok && !projection {
+		err = d.UnmarshalJSONPack(data)
+		if err != nil {
+			err = errors.WithStack(&DecodeError{s.Name, err})
+		}
+		return
+	}
+
 	buf := ibuf.From(data)
 	switch d := v.(type) {
 	case *map[string]interface{}:
@@ -92,13 +123,13 @@ func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err erro
 		}
 
 	case *interface{}:
-		return s.decode(data, *d, false)
+		return s.decode(data, *d, false, projection)
 
 	default:
 		switch vKind {
 		case reflect.Struct:
 			var sop *structOperation
-			sop, err = s.getStructOperation(vType, v)
+			sop, err = s.getStructOperation(vType, v, projection)
 			if err != nil {
 				return errors.WithStack(&DecodeError{s.Name, err})
 			}
@@ -109,7 +140,7 @@ func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err erro
 			vType = sliceType.Elem()
 			if vType.Kind() == reflect.Struct {
 				var sop *structOperation
-				sop, err = s.getStructOperation(vType, v)
+				sop, err = s.getStructOperation(vType, v, projection)
 				if err != nil {
 					return errors.WithStack(&DecodeError{s.Name, err})
 				}
@@ -125,7 +156,7 @@ func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err erro
 			vType = sliceType.Elem()
 			if vType.Kind() == reflect.Struct {
 				var sop *structOperation
-				sop, err = s.getStructOperation(vType, v)
+				sop, err = s.getStructOperation(vType, v, projection)
 				if err != nil {
 					return errors.WithStack(&DecodeError{s.Name, err})
 				}
@@ -144,7 +175,7 @@ func (s *Schema) decode(data []byte, v interface{}, checkPtrType bool) (err erro
 			if elemType.Kind() == reflect.Array {
 				_, err = _arrayOp.decodeDynamic(buf, s.rootOp, v)
 			} else {
-				return s.decode(data, elemType.Indirect(v), false)
+				return s.decode(data, elemType.Indirect(v), false, projection)
 			}
 
 		default:
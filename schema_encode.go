@@ -68,7 +68,37 @@ func (s *Schema) Marshal(d interface{}) ([]byte, error) {
 // This method is useful with buffer pool for saving memory allocation usage and improving performance.
 //
 // Caution: the encoder might re-allocate and grow the slice if necessary, the length and capacity of slice might be changed.
-func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
+func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) error {
+	buf := ibuf.From(*dataPtr)
+	err := s.encodeBuffer(buf, d)
+	if err != nil {
+		return err
+	}
+
+	// enlarge default encoder buffer allocation with latest encoded result
+	s.encodeBufSize = maxInt64(s.encodeBufSize, buf.Offset())
+
+	*dataPtr = buf.Seal()
+	return nil
+}
+
+// encodeWithHeader encodes a varuint header followed by encoded data of d.
+func (s *Schema) encodeWithHeader(header uint64, d interface{}) ([]byte, error) {
+	buf := ibuf.From(make([]byte, s.encodeBufSize))
+	buf.WriteVarUint(header)
+	err := s.encodeBuffer(buf, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// enlarge default encoder buffer allocation with latest encoded result
+	s.encodeBufSize = maxInt64(s.encodeBufSize, buf.Offset())
+
+	return buf.Seal(), nil
+}
+
+// encodeBuffer encodes d into buf at current offset of buf.
+func (s *Schema) encodeBuffer(buf *ibuf.Buffer, d interface{}) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			switch r := r.(type) {
@@ -80,7 +110,12 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 		}
 	}()
 
-	buf := ibuf.From(*dataPtr)
+	if m, ok := s.marshalerOf(d); ok {
+		if err = s.encodeMarshaler(buf, m); err != nil {
+			err = errors.WithStack(&EncodeError{s.Name, err})
+		}
+		return
+	}
 
 	switch d := d.(type) {
 	// fast path: use type assertion, it's faster then reflection
@@ -103,7 +138,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 		err = s.encodeDynamic(buf, s.rootOp, *d)
 
 	case *interface{}:
-		return s.EncodeTo(*d, dataPtr)
+		return s.encodeBuffer(buf, *d)
 
 	default:
 		// slow path: use reflection to check type
@@ -111,7 +146,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 		switch dType.Kind() {
 		case reflect.Struct:
 			var sop *structOperation
-			sop, err = s.getStructOperation(dType, d)
+			sop, err = s.getStructOperation(dType, d, false)
 			if err != nil {
 				err = errors.WithStack(&EncodeError{s.Name, err})
 				return
@@ -124,7 +159,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 			switch dType.Kind() {
 			case reflect.Struct:
 				var sop *structOperation
-				sop, err = s.getStructOperation(dType, d)
+				sop, err = s.getStructOperation(dType, d, false)
 				if err != nil {
 					err = errors.WithStack(&EncodeError{s.Name, err})
 					return
@@ -144,7 +179,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 			switch dType.Kind() {
 			case reflect.Struct:
 				var sop *structOperation
-				sop, err = s.getStructOperation(dType, d)
+				sop, err = s.getStructOperation(dType, d, false)
 				if err != nil {
 					err = errors.WithStack(&EncodeError{s.Name, err})
 					return
@@ -160,7 +195,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 
 		case reflect.Ptr:
 			elemType := toPtrElemType(dType)
-			return s.EncodeTo(elemType.Indirect(d), dataPtr)
+			return s.encodeBuffer(buf, elemType.Indirect(d))
 		default:
 			err = errors.WithStack(&EncodeError{s.Name, &WrongTypeError{dType.String()}})
 			return
@@ -169,13 +204,7 @@ func (s *Schema) EncodeTo(d interface{}, dataPtr *[]byte) (err error) {
 
 	if err != nil {
 		err = errors.WithStack(&EncodeError{s.Name, err})
-		return
 	}
-
-	// enlarge default encoder buffer allocation with latest encoded result
-	s.encodeBufSize = maxInt64(s.encodeBufSize, buf.Offset())
-
-	*dataPtr = buf.Seal()
 	return
 }
 
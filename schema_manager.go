@@ -1,14 +1,54 @@
 package jsonpack
 
 import (
+	"sort"
 	"sync"
 
+	"github.com/modern-go/reflect2"
 	"github.com/pkg/errors"
 )
 
 // schemaManager manages schema instances
 type schemaManager struct {
-	schemas sync.Map // provides thread safety map
+	schemas       sync.Map   // provides thread safety map, stores *schemaVersions with schema name as key
+	envelopeIDs   sync.Map   // stores *envelopeInfo with envelope id as key
+	envelopeNames sync.Map   // stores *envelopeInfo with schema name as key
+	mu            sync.Mutex // serializes modifications of schema versions and envelopes
+}
+
+// envelopeInfo represents a schema registered for envelope encoding.
+type envelopeInfo struct {
+	id   uint64
+	name string
+	typ  reflect2.Type // the type to decode into, nil means decoding into map or slice
+}
+
+// schemaVersions contains all compiled versions of a schema.
+//
+// It's immutable once it has been stored in schema manager, modifications create
+// a new copy and replace the stored one, so readers never need to acquire lock.
+type schemaVersions struct {
+	latest   *Schema
+	versions map[uint32]*Schema
+}
+
+// clone returns a copy of schema versions
+func (v *schemaVersions) clone() *schemaVersions {
+	newVers := &schemaVersions{latest: v.latest, versions: make(map[uint32]*Schema, len(v.versions)+1)}
+	for ver, schema := range v.versions {
+		newVers.versions[ver] = schema
+	}
+	return newVers
+}
+
+// updateLatest points latest schema to the schema with highest version number
+func (v *schemaVersions) updateLatest() {
+	v.latest = nil
+	for _, schema := range v.versions {
+		if v.latest == nil || schema.Version > v.latest.Version {
+			v.latest = schema
+		}
+	}
 }
 
 // newSchemaManager returns a new schema manager instance
@@ -16,11 +56,11 @@ func newSchemaManager() *schemaManager {
 	return &schemaManager{}
 }
 
-// getAll returns a cloned map of schema instances
+// getAll returns a cloned map of latest version of schema instances
 func (s *schemaManager) getAllSchemas() map[string]*Schema {
 	schemas := make(map[string]*Schema)
 	s.schemas.Range(func(key, value interface{}) bool {
-		schemas[key.(string)] = value.(*Schema)
+		schemas[key.(string)] = value.(*schemaVersions).latest
 		return true
 	})
 	return schemas
@@ -31,7 +71,7 @@ func (s *schemaManager) getAllSchemas() map[string]*Schema {
 func (s *schemaManager) getAllSchemaDefs() map[string]*SchemaDef {
 	schDefs := make(map[string]*SchemaDef)
 	s.schemas.Range(func(key, value interface{}) bool {
-		schema := value.(*Schema)
+		schema := value.(*schemaVersions).latest
 		schDef, err := schema.GetSchemaDef()
 		if err == nil {
 			schDefs[key.(string)] = schDef
@@ -47,23 +87,66 @@ func (s *schemaManager) getAllSchemaDefs() map[string]*SchemaDef {
 func (s *schemaManager) getAllSchemaDefTexts() map[string][]byte {
 	schDefTexts := make(map[string][]byte)
 	s.schemas.Range(func(key, value interface{}) bool {
-		schema := value.(*Schema)
+		schema := value.(*schemaVersions).latest
 		schDefTexts[key.(string)] = schema.GetSchemaDefText()
 		return true
 	})
 	return schDefTexts
 }
 
-// get returns schema instance in schema manager or returns nil if schema not found.
-func (s *schemaManager) get(name string) *Schema {
+// getVersions returns all versions of schema, or returns nil if schema not found.
+func (s *schemaManager) getVersions(name string) *schemaVersions {
 	instance, ok := s.schemas.Load(name)
 	if !ok {
 		return nil
 	}
-	return instance.(*Schema)
+	return instance.(*schemaVersions)
+}
+
+// get returns latest version of schema instance in schema manager or returns nil if schema not found.
+func (s *schemaManager) get(name string) *Schema {
+	vers := s.getVersions(name)
+	if vers == nil {
+		return nil
+	}
+	return vers.latest
 }
 
-// add a new schema or or replace existing one, compile and store it in schema manager.
+// getVersion returns specified version of schema instance in schema manager
+// or returns nil if schema or version not found.
+func (s *schemaManager) getVersion(name string, version uint32) *Schema {
+	vers := s.getVersions(name)
+	if vers == nil {
+		return nil
+	}
+	return vers.versions[version]
+}
+
+// latestVersion returns the highest version number of schema, the ok is false if schema not found.
+func (s *schemaManager) latestVersion(name string) (version uint32, ok bool) {
+	vers := s.getVersions(name)
+	if vers == nil {
+		return 0, false
+	}
+	return vers.latest.Version, true
+}
+
+// listVersions returns all version numbers of schema in ascending order, or returns nil if schema not found.
+func (s *schemaManager) listVersions(name string) []uint32 {
+	vers := s.getVersions(name)
+	if vers == nil {
+		return nil
+	}
+	list := make([]uint32, 0, len(vers.versions))
+	for ver := range vers.versions {
+		list = append(list, ver)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// add a new schema or replace the latest version of existing one, compile and store it in schema manager.
+// The new schema will be version 1 if schema doesn't exist.
 // It returns error if can't not build new schema and it will not add to schemna manager.
 func (s *schemaManager) add(name string, v ...interface{}) (*Schema, error) {
 	schema := newSchema(name, v...)
@@ -72,26 +155,155 @@ func (s *schemaManager) add(name string, v ...interface{}) (*Schema, error) {
 		return nil, err
 	}
 
-	s.schemas.Delete(name)
-	s.schemas.Store(name, schema)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schema.Version = 1
+	if vers := s.getVersions(name); vers != nil {
+		schema.Version = vers.latest.Version
+	}
+	s.store(schema)
+
+	return schema, nil
+}
+
+// addVersion adds a new version of schema or replace existing one with the same version,
+// compile and store it in schema manager.
+// It returns error if can't not build new schema and it will not add to schemna manager.
+func (s *schemaManager) addVersion(name string, version uint32, v ...interface{}) (*Schema, error) {
+	schema := newSchema(name, v...)
+	schema.Version = version
+	err := schema.build()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(schema)
 
 	return schema, nil
 }
 
+// store stores schema as a version of schema, caller must hold s.mu.
+func (s *schemaManager) store(schema *Schema) {
+	var vers *schemaVersions
+	if oldVers := s.getVersions(schema.Name); oldVers != nil {
+		vers = oldVers.clone()
+	} else {
+		vers = &schemaVersions{versions: make(map[uint32]*Schema, 1)}
+	}
+	vers.versions[schema.Version] = schema
+	vers.updateLatest()
+
+	s.schemas.Store(schema.Name, vers)
+}
+
 // remove schema by name in schema manager, it returns *SchemaNonExistError error
 // if schema doesn't exist.
 func (s *schemaManager) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	_, ok := s.schemas.LoadAndDelete(name)
 	if !ok {
 		return errors.WithStack(&SchemaNonExistError{name})
 	}
+	s.unregisterEnvelope(name)
+	return nil
+}
+
+// removeVersion removes a version of schema in schema manager, the schema will be removed
+// if there is no version left. It returns *SchemaVersionNonExistError error if the version
+// of schema doesn't exist.
+func (s *schemaManager) removeVersion(name string, version uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldVers := s.getVersions(name)
+	if oldVers == nil {
+		return errors.WithStack(&SchemaVersionNonExistError{name, version})
+	}
+	if _, ok := oldVers.versions[version]; !ok {
+		return errors.WithStack(&SchemaVersionNonExistError{name, version})
+	}
+
+	if len(oldVers.versions) == 1 {
+		s.schemas.Delete(name)
+		s.unregisterEnvelope(name)
+		return nil
+	}
+
+	vers := oldVers.clone()
+	delete(vers.versions, version)
+	vers.updateLatest()
+	s.schemas.Store(name, vers)
+
+	return nil
+}
+
+// registerEnvelope assigns envelope id and the type to decode into to schema.
+// The previous envelope id of schema will be released.
+func (s *schemaManager) registerEnvelope(name string, id uint64, typ reflect2.Type) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info, ok := s.envelopeIDs.Load(id); ok && info.(*envelopeInfo).name != name {
+		return errors.Errorf("envelope id %d has been registered by schema '%s'", id, info.(*envelopeInfo).name)
+	}
+	if info, ok := s.envelopeNames.Load(name); ok {
+		s.envelopeIDs.Delete(info.(*envelopeInfo).id)
+	}
+
+	info := &envelopeInfo{id: id, name: name, typ: typ}
+	s.envelopeIDs.Store(id, info)
+	s.envelopeNames.Store(name, info)
+
 	return nil
 }
 
-// reset removes all schema instance in schema manager.
+// unregisterEnvelope releases envelope id of schema, caller must hold s.mu.
+func (s *schemaManager) unregisterEnvelope(name string) {
+	if info, ok := s.envelopeNames.LoadAndDelete(name); ok {
+		s.envelopeIDs.Delete(info.(*envelopeInfo).id)
+	}
+}
+
+// getEnvelopeByID returns envelope information by envelope id, or returns nil if id not found.
+func (s *schemaManager) getEnvelopeByID(id uint64) *envelopeInfo {
+	info, ok := s.envelopeIDs.Load(id)
+	if !ok {
+		return nil
+	}
+	return info.(*envelopeInfo)
+}
+
+// getEnvelopeByName returns envelope information by schema name, or returns nil if schema
+// hasn't registered.
+func (s *schemaManager) getEnvelopeByName(name string) *envelopeInfo {
+	info, ok := s.envelopeNames.Load(name)
+	if !ok {
+		return nil
+	}
+	return info.(*envelopeInfo)
+}
+
+// reset removes all schema instance and envelope information in schema manager.
 func (s *schemaManager) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.schemas.Range(func(key, value interface{}) bool {
 		s.schemas.Delete(key)
 		return true
 	})
+	s.envelopeIDs.Range(func(key, value interface{}) bool {
+		s.envelopeIDs.Delete(key)
+		return true
+	})
+	s.envelopeNames.Range(func(key, value interface{}) bool {
+		s.envelopeNames.Delete(key)
+		return true
+	})
 }
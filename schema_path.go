@@ -0,0 +1,346 @@
+package jsonpack
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+// pathElem represents an element of property path, it's either a property name or an array index.
+type pathElem struct {
+	name    string
+	index   uint64
+	isIndex bool
+}
+
+// parsePath parses property path likes "user.name", "items[2].sku" or "[0].name" into path elements.
+// An empty path represents the whole document.
+func parsePath(path string) ([]pathElem, error) {
+	elems := make([]pathElem, 0, strings.Count(path, ".")+strings.Count(path, "[")+1)
+	i := 0
+	for i < len(path) {
+		switch path[i] {
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("missing ']' at position %d", i)
+			}
+			index, err := strconv.ParseUint(path[i+1:i+end], 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid array index '%s' at position %d", path[i+1:i+end], i+1)
+			}
+			elems = append(elems, pathElem{index: index, isIndex: true})
+			i += end + 1
+
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, errors.Errorf("empty property name at position %d", i+1)
+			}
+			i++
+
+		default:
+			if i > 0 && path[i-1] == ']' {
+				return nil, errors.Errorf("missing '.' at position %d", i)
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			elems = append(elems, pathElem{name: path[i : i+end]})
+			i += end
+		}
+	}
+	return elems, nil
+}
+
+// formatPath appends a property name or array index to path.
+func formatPath(path string, elem pathElem) string {
+	if elem.isIndex {
+		return path + "[" + strconv.FormatUint(elem.index, 10) + "]"
+	}
+	if path == "" {
+		return elem.name
+	}
+	return path + "." + elem.name
+}
+
+// locateValue moves offset of buf to the start of encoded value of path,
+// and returns the operation which describes the value.
+func locateValue(buf *ibuf.Buffer, opNode *operation, elems []pathElem) (*operation, error) {
+	var err error
+	var path string
+	for _, elem := range elems {
+		if elem.isIndex {
+			if !isArrayOp(opNode) {
+				return nil, errors.Errorf("'%s' is not an array", path)
+			}
+			var length uint64
+			length, err = readLength(buf)
+			if err != nil {
+				return nil, err
+			}
+			if elem.index >= length {
+				return nil, errors.Errorf("index %d of '%s' out of range, length: %d", elem.index, path, length)
+			}
+			opNode = opNode.children[0]
+			err = skipItems(buf, opNode, elem.index)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if opNode.handlerType != objectOpType {
+				return nil, errors.Errorf("'%s' is not an object", path)
+			}
+			idx := findProperty(opNode, elem.name)
+			if idx < 0 {
+				return nil, errors.Errorf("property '%s' doesn't exist", formatPath(path, elem))
+			}
+			for _, childNode := range opNode.children[:idx] {
+				err = skipValue(buf, childNode)
+				if err != nil {
+					return nil, err
+				}
+			}
+			opNode = opNode.children[idx]
+		}
+		path = formatPath(path, elem)
+	}
+	return opNode, nil
+}
+
+// locate parses path and moves offset of buf to the start of encoded value of path.
+func (s *Schema) locate(buf *ibuf.Buffer, path string) (*operation, error) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, errors.WithStack(&PathError{s.Name, path, err})
+	}
+	opNode, err := locateValue(buf, s.rootOp, elems)
+	if err != nil {
+		return nil, errors.WithStack(&PathError{s.Name, path, err})
+	}
+	return opNode, nil
+}
+
+// readError converts error of reading value at path into PathError, except WrongTypeError
+// which is returned as it is.
+func (s *Schema) readError(path string, err error) error {
+	var typeErr *WrongTypeError
+	if errors.As(err, &typeErr) {
+		return err
+	}
+	return errors.WithStack(&PathError{s.Name, path, err})
+}
+
+// readStringValue reads string value described by opNode from buf,
+// the returned string shares the underlying memory with buf.
+func readStringValue(buf *ibuf.Buffer, opNode *operation) (string, error) {
+	if opNode.handlerType != stringOpType {
+		return "", errors.WithStack(&WrongTypeError{opTypeName(opNode.handlerType)})
+	}
+	length, err := readLength(buf)
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(remainBytes(buf)) {
+		return "", errors.WithStack(ibuf.BufferOverreadError)
+	}
+	// read string without copy
+	data := buf.ReadBytes(int64(length))
+	return *(*string)(unsafe.Pointer(&data)), nil //nolint:gosec
+}
+
+// readBoolValue reads boolean value described by opNode from buf.
+func readBoolValue(buf *ibuf.Buffer, opNode *operation) (bool, error) {
+	if opNode.handlerType != booleanOpType {
+		return false, errors.WithStack(&WrongTypeError{opTypeName(opNode.handlerType)})
+	}
+	if remainBytes(buf) < 1 {
+		return false, errors.WithStack(ibuf.BufferOverreadError)
+	}
+	return buf.ReadByte() != 0, nil
+}
+
+// readNumberValue reads number value described by opNode from buf.
+func readNumberValue(buf *ibuf.Buffer, opNode *operation) (number, error) {
+	if !isNumberType(opNode.handlerType) {
+		return number{}, errors.WithStack(&WrongTypeError{opTypeName(opNode.handlerType)})
+	}
+	if opNode.fixedSize > remainBytes(buf) {
+		return number{}, errors.WithStack(ibuf.BufferOverreadError)
+	}
+	return readNumber(buf, opNode.handlerType), nil
+}
+
+// readInt64Value reads number value described by opNode from buf and converts it to int64.
+func readInt64Value(buf *ibuf.Buffer, opNode *operation) (int64, error) {
+	n, err := readNumberValue(buf, opNode)
+	if err != nil {
+		return 0, err
+	}
+	val, ok := n.toInt(math.MinInt64, math.MaxInt64)
+	if !ok {
+		return 0, errors.Errorf("value %s overflows int64 type", n)
+	}
+	return val, nil
+}
+
+// readUint64Value reads number value described by opNode from buf and converts it to uint64.
+func readUint64Value(buf *ibuf.Buffer, opNode *operation) (uint64, error) {
+	n, err := readNumberValue(buf, opNode)
+	if err != nil {
+		return 0, err
+	}
+	val, ok := n.toUint(math.MaxUint64)
+	if !ok {
+		return 0, errors.Errorf("value %s overflows uint64 type", n)
+	}
+	return val, nil
+}
+
+// readFloat64Value reads number value described by opNode from buf and converts it to float64.
+func readFloat64Value(buf *ibuf.Buffer, opNode *operation) (float64, error) {
+	n, err := readNumberValue(buf, opNode)
+	if err != nil {
+		return 0, err
+	}
+	return n.toFloat(), nil
+}
+
+/*
+Get reads the value of property specified by path from encoded data without decoding whole data.
+
+It skips over the values before the property and only decodes the value of property,
+it's much faster than Decode method when only a few properties are needed.
+
+The path is property names separated by dot, and array index in square brackets, likes "user.name",
+"items[2].sku", or "[0].name" for array schema. An empty path represents the whole document.
+
+The type of return value is the same as decoding into map, likes string, uint32, map[string]interface{}
+and []interface{}. The returned string shares the underlying memory with data.
+
+It returns *PathError error if path is invalid, doesn't exist in schema or data, or the value of
+property can't be decoded.
+
+Example:
+	// gets the sku of the third item
+	sku, err := sch.Get(encodedData, "items[2].sku")
+*/
+func (s *Schema) Get(data []byte, path string) (interface{}, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := decodeValue(buf, opNode)
+	if err != nil {
+		return nil, errors.WithStack(&PathError{s.Name, path, err})
+	}
+	return v, nil
+}
+
+// decodeValue decodes the value described by opNode from buf.
+func decodeValue(buf *ibuf.Buffer, opNode *operation) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case string:
+				err = errors.New(r)
+			case error:
+				err = r
+			}
+		}
+	}()
+	return opNode.handler.decodeDynamic(buf, opNode, _createNewData(buf, opNode))
+}
+
+// GetString reads the string value of property specified by path from encoded data,
+// the returned string shares the underlying memory with data.
+//
+// It returns *PathError error if path is invalid or doesn't exist, and returns
+// *WrongTypeError error if the type of property is not string.
+func (s *Schema) GetString(data []byte, path string) (string, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return "", err
+	}
+	val, err := readStringValue(buf, opNode)
+	if err != nil {
+		return "", s.readError(path, err)
+	}
+	return val, nil
+}
+
+// GetBool reads the boolean value of property specified by path from encoded data.
+//
+// It returns *PathError error if path is invalid or doesn't exist, and returns
+// *WrongTypeError error if the type of property is not boolean.
+func (s *Schema) GetBool(data []byte, path string) (bool, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return false, err
+	}
+	val, err := readBoolValue(buf, opNode)
+	if err != nil {
+		return false, s.readError(path, err)
+	}
+	return val, nil
+}
+
+// GetInt64 reads the value of property specified by path from encoded data, and converts it to int64.
+//
+// It returns *PathError error if path is invalid or doesn't exist, or the value can't be represented
+// by int64, and returns *WrongTypeError error if the type of property is not number type.
+func (s *Schema) GetInt64(data []byte, path string) (int64, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return 0, err
+	}
+	val, err := readInt64Value(buf, opNode)
+	if err != nil {
+		return 0, s.readError(path, err)
+	}
+	return val, nil
+}
+
+// GetUint64 reads the value of property specified by path from encoded data, and converts it to uint64.
+//
+// It returns *PathError error if path is invalid or doesn't exist, or the value can't be represented
+// by uint64, and returns *WrongTypeError error if the type of property is not number type.
+func (s *Schema) GetUint64(data []byte, path string) (uint64, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return 0, err
+	}
+	val, err := readUint64Value(buf, opNode)
+	if err != nil {
+		return 0, s.readError(path, err)
+	}
+	return val, nil
+}
+
+// GetFloat64 reads the value of property specified by path from encoded data, and converts it to float64.
+//
+// It returns *PathError error if path is invalid or doesn't exist, and returns *WrongTypeError
+// error if the type of property is not number type.
+func (s *Schema) GetFloat64(data []byte, path string) (float64, error) {
+	buf := ibuf.From(data)
+	opNode, err := s.locate(buf, path)
+	if err != nil {
+		return 0, err
+	}
+	val, err := readFloat64Value(buf, opNode)
+	if err != nil {
+		return 0, s.readError(path, err)
+	}
+	return val, nil
+}
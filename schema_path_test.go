@@ -0,0 +1,112 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestGet(t *testing.T) {
+	var err error
+	var expectErr *PathError
+
+	sch := jsonPack.GetSchema("complex")
+	data := testdata.ComplexExpData
+
+	tests := []struct {
+		path   string
+		expect interface{}
+	}{
+		{"category", uint32(1)},
+		{"ips[2]", "192.168.1.3"},
+		{"positions[3]", uint8(201)},
+		{"user.currentStatus.msg", "test message"},
+		{"user.currentStatus", map[string]interface{}{"group": "admin_group", "msg": "test message"}},
+	}
+	for _, test := range tests {
+		v, err := sch.Get(data, test.path)
+		if err != nil {
+			t.Fatalf("Get %s fail, err: %+v", test.path, err)
+		}
+		if m, ok := test.expect.(map[string]interface{}); ok {
+			if !compareMap(m, v.(map[string]interface{})) {
+				t.Errorf("Get %s, expect: %v, got: %v", test.path, m, v)
+			}
+		} else if v != test.expect {
+			t.Errorf("Get %s, expect: %v, got: %v", test.path, test.expect, v)
+		}
+	}
+
+	// whole document
+	v, err := sch.Get(data, "")
+	if err != nil {
+		t.Fatalf("Get whole document fail, err: %+v", err)
+	}
+	if !compareMap(testdata.ComplexData, v.(map[string]interface{})) {
+		t.Errorf("Get whole document, expect: %v, got: %v", testdata.ComplexData, v)
+	}
+
+	str, err := sch.GetString(data, "user.email")
+	if err != nil || str != "test@example.com" {
+		t.Errorf("GetString fail, got: %s, err: %+v", str, err)
+	}
+	num, err := sch.GetInt64(data, "positions[2]")
+	if err != nil || num != 200 {
+		t.Errorf("GetInt64 fail, got: %d, err: %+v", num, err)
+	}
+	unum, err := sch.GetUint64(data, "category")
+	if err != nil || unum != 1 {
+		t.Errorf("GetUint64 fail, got: %d, err: %+v", unum, err)
+	}
+
+	// array schema
+	sch = jsonPack.GetSchema("sliceObject")
+	str, err = sch.GetString(testdata.SliceExpData, "[1].obj.name")
+	if err != nil || str != "test2" {
+		t.Errorf("GetString of array schema fail, got: %s, err: %+v", str, err)
+	}
+	fnum, err := sch.GetFloat64(testdata.SliceExpData, "[2].num2")
+	if err != nil || fnum != 3.56 {
+		t.Errorf("GetFloat64 fail, got: %f, err: %+v", fnum, err)
+	}
+
+	invalidPaths := []string{"[3].num1", "[0].unknown", "[0].num1.name", "[0]obj", "[a]", "[0", "[0]..obj", "[0].obj."}
+	for _, path := range invalidPaths {
+		_, err = sch.Get(testdata.SliceExpData, path)
+		if !errors.As(err, &expectErr) {
+			t.Errorf("Get invalid path %s should fail, err: %v", path, err)
+		}
+	}
+
+	// truncated data
+	_, err = sch.GetString(testdata.SliceExpData[:len(testdata.SliceExpData)-1], "[2].obj.name")
+	if !errors.As(err, &expectErr) {
+		t.Errorf("GetString from truncated data should fail, err: %v", err)
+	}
+	_, err = sch.Get(testdata.SliceExpData[:len(testdata.SliceExpData)-1], "[2].obj")
+	if !errors.As(err, &expectErr) || expectErr.Path != "[2].obj" {
+		t.Errorf("Get from truncated data should fail with path, err: %v", err)
+	}
+
+	var typeErr *WrongTypeError
+	_, err = sch.GetString(testdata.SliceExpData, "[0].num1")
+	if !errors.As(err, &typeErr) {
+		t.Errorf("GetString of int32le property should fail, err: %v", err)
+	}
+
+	// the length prefix of string isn't encoded in minimal bytes
+	sch, err = NewJSONPack().AddSchema("overlong", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+		"order":      []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	str, err = sch.GetString([]byte{0x82, 0x00, 'a', 'b'}, "name")
+	if err != nil || str != "ab" {
+		t.Errorf("GetString with overlong length prefix fail, got: %s, err: %+v", str, err)
+	}
+}
@@ -8,31 +8,47 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *Schema) getStructOperation(typ reflect2.Type, st interface{}) (*structOperation, error) {
+func (s *Schema) getStructOperation(typ reflect2.Type, st interface{}, projection bool) (*structOperation, error) {
+	cache := s.structOpCache
+	if projection {
+		cache = s.projectionOpCache
+	}
 	cacheKey := typ.RType()
-	sop, ok := s.structOpCache.Load(cacheKey)
+	sop, ok := cache.Load(cacheKey)
 	// sop, ok := s.structOpCache[cacheKey]
 	if !ok {
-		newOp, err := s.buildStructOperation(s.rootOp, st)
+		newOp, err := s.buildStructOperation(s.rootOp, st, projection)
 		if err != nil {
 			return nil, err
 		}
-		s.structOpCache.Store(cacheKey, newOp)
+		cache.Store(cacheKey, newOp)
 		return newOp, nil
 	}
 	return sop.(*structOperation), nil
 }
 
-func (s *Schema) buildStructOperation(op *operation, st interface{}) (*structOperation, error) {
+func (s *Schema) buildStructOperation(op *operation, st interface{}, projection bool) (*structOperation, error) {
 	sop := newStructOperation(_nullOp, nullOpType)
-	err := s._buildStructOperation(sop, op, reflect2.TypeOf(st))
+	err := s._buildStructOperation(sop, op, reflect2.TypeOf(st), projection)
 	if err != nil {
 		return nil, err
 	}
+	if projection && sop.handlerType == structOpType {
+		// the remaining values after the last field of root struct are unnecessary to skip
+		n := len(sop.children)
+		for n > 0 && sop.children[n-1].handlerType == skipOpType {
+			n--
+		}
+		sop.children = sop.children[:n]
+	}
 	return sop, nil
 }
 
-func (s *Schema) _buildStructOperation(sop *structOperation, op *operation, typ reflect2.Type) error {
+// _buildStructOperation builds struct operation of typ from operation op recursively.
+//
+// If projection is true, the properties which don't exist in struct will be skipped
+// instead of returning StructFieldNonExistError error.
+func (s *Schema) _buildStructOperation(sop *structOperation, op *operation, typ reflect2.Type, projection bool) error {
 	var err error
 	sop.handler = op.handler
 	sop.handlerType = op.handlerType
@@ -62,10 +78,17 @@ func (s *Schema) _buildStructOperation(sop *structOperation, op *operation, typ
 			childOp := newStructOperation(opNode.handler, opNode.handlerType)
 			childOp.field = structType.FieldByName(fieldMap[opNode.propName])
 			if childOp.field == nil {
-				return errors.WithStack(&StructFieldNonExistError{typ.String(), opNode.propName})
+				if !projection {
+					return errors.WithStack(&StructFieldNonExistError{typ.String(), opNode.propName})
+				}
+				childOp.handler = _skipOp
+				childOp.handlerType = skipOpType
+				childOp.opInstance = opNode
+				sop.appendChild(childOp)
+				continue
 			}
 			// childOp.fieldType = childOp.field.Type()
-			err = s._buildStructOperation(childOp, opNode, childOp.field.Type())
+			err = s._buildStructOperation(childOp, opNode, childOp.field.Type(), projection)
 			if err != nil {
 				return err
 			}
@@ -79,7 +102,7 @@ func (s *Schema) _buildStructOperation(sop *structOperation, op *operation, typ
 			sop.handlerType = arrayOpType
 			itemOp := op.children[0]
 			childOp := newStructOperation(itemOp.handler, itemOp.handlerType)
-			err = s._buildStructOperation(childOp, itemOp, typ.Elem())
+			err = s._buildStructOperation(childOp, itemOp, typ.Elem(), projection)
 			if err != nil {
 				return err
 			}
@@ -90,7 +113,7 @@ func (s *Schema) _buildStructOperation(sop *structOperation, op *operation, typ
 			sop.handlerType = sliceOpType
 			itemOp := op.children[0]
 			childOp := newStructOperation(itemOp.handler, itemOp.handlerType)
-			err = s._buildStructOperation(childOp, itemOp, typ.Elem())
+			err = s._buildStructOperation(childOp, itemOp, typ.Elem(), projection)
 			if err != nil {
 				return err
 			}
@@ -142,9 +165,11 @@ func parseJsonTag(field *reflect2.StructField) (*jsonTag, bool) {
 }
 func cloneAnonymousObjectOp(op *operation) *operation {
 	newOp := operation{
-		propName: "",
-		handler:  op.handler,
-		children: op.children,
+		propName:    "",
+		handler:     op.handler,
+		handlerType: op.handlerType,
+		children:    op.children,
+		fixedSize:   op.fixedSize,
 	}
 	return &newOp
 }
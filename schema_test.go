@@ -490,3 +490,107 @@ func compareBytes(t *testing.T, a []byte, b []byte) bool {
 	}
 	return true
 }
+
+func TestDecodeProjection(t *testing.T) {
+	var err error
+
+	type status struct {
+		Msg string `json:"msg"`
+	}
+	type account struct {
+		Email         string `json:"email"`
+		CurrentStatus status `json:"currentStatus"`
+	}
+	type projection struct {
+		Positions []uint8   `json:"positions"`
+		Accounts  []account `json:"accounts"`
+	}
+	type category struct {
+		Category uint32 `json:"category"`
+	}
+
+	// struct lacks properties of schema should fail in Decode method
+	data := projection{}
+	err = jsonPack.Decode("complex", testdata.ComplexExpData, &data)
+	var expectErr *StructFieldNonExistError
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Decode partial struct should fail, err: %v", err)
+	}
+
+	err = jsonPack.DecodeProjection("complex", testdata.ComplexExpData, &data)
+	if err != nil {
+		t.Fatalf("DecodeProjection fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(data.Positions, testdata.ComplexStructData.Positions) {
+		t.Errorf("DecodeProjection positions, expect: %v, got: %v", testdata.ComplexStructData.Positions, data.Positions)
+	}
+	if len(data.Accounts) != len(testdata.ComplexStructData.Accounts) {
+		t.Fatalf("DecodeProjection accounts, expect length: %d, got: %d", len(testdata.ComplexStructData.Accounts), len(data.Accounts))
+	}
+	for i, acct := range testdata.ComplexStructData.Accounts {
+		if data.Accounts[i].Email != acct.Email || data.Accounts[i].CurrentStatus.Msg != acct.CurrentStatus.Msg {
+			t.Errorf("DecodeProjection accounts[%d], expect: %+v, got: %+v", i, acct, data.Accounts[i])
+		}
+	}
+
+	// the rest of data after the last field doesn't need to be read
+	cat := category{}
+	err = jsonPack.DecodeProjection("complex", testdata.ComplexExpData[:4], &cat)
+	if err != nil || cat.Category != testdata.ComplexStructData.Category {
+		t.Errorf("DecodeProjection category fail, got: %d, err: %+v", cat.Category, err)
+	}
+
+	// slice of struct
+	type sliceItem struct {
+		Num2 float64 `json:"num2"`
+	}
+	items := []sliceItem{}
+	err = jsonPack.DecodeProjection("sliceObject", testdata.SliceExpData, &items)
+	if err != nil {
+		t.Fatalf("DecodeProjection slice fail, err: %+v", err)
+	}
+	if len(items) != 3 || items[0].Num2 != 1.56 || items[2].Num2 != 3.56 {
+		t.Errorf("DecodeProjection slice fail, got: %+v", items)
+	}
+
+	// full struct works as Decode method
+	structData := testdata.Complex{}
+	err = jsonPack.DecodeProjection("complex", testdata.ComplexExpData, &structData)
+	if err != nil {
+		t.Errorf("DecodeProjection full struct fail, err: %+v", err)
+	}
+	if !reflect.DeepEqual(&structData, &testdata.ComplexStructData) {
+		t.Errorf("DecodeProjection full struct data fail")
+	}
+}
+
+func TestInt8FieldType(t *testing.T) {
+	type int8St struct {
+		Delta int8  `json:"delta"`
+		Count uint8 `json:"count"`
+	}
+	sch, err := NewJSONPack().AddSchema("int8St", int8St{})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	schDef, err := sch.GetSchemaDef()
+	if err != nil {
+		t.Fatalf("GetSchemaDef fail, err: %+v", err)
+	}
+	if typ := schDef.Properties["delta"].Type; typ != "int8" {
+		t.Errorf("type of int8 field: '%s', expect: 'int8'", typ)
+	}
+
+	data, err := sch.Encode(&int8St{Delta: -3, Count: 250})
+	if err != nil {
+		t.Fatalf("Encode fail, err: %+v", err)
+	}
+	result := make(map[string]interface{})
+	err = sch.Decode(data, &result)
+	if err != nil {
+		t.Fatalf("Decode fail, err: %+v", err)
+	}
+	if result["delta"] != int8(-3) || result["count"] != uint8(250) {
+		t.Errorf("Decode result: %v, expect: map[count:250 delta:-3]", result)
+	}
+}
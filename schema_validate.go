@@ -0,0 +1,104 @@
+package jsonpack
+
+import (
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+Validate checks whether data is a valid encoded data of schema without decoding it.
+
+It walks the schema definition over the encoded data and verifies:
+
+* Every length prefix of string and array is valid and doesn't exceed the size of data.
+
+* Every string is valid UTF-8 string.
+
+* Every boolean value is either 0 or 1.
+
+* The data is consumed exactly, there is no trailing bytes.
+
+It doesn't allocate maps or structs, so it's cheap to reject corrupted data before decoding it.
+It returns *ValidationError error if data is invalid.
+*/
+func (s *Schema) Validate(data []byte) error {
+	buf := ibuf.From(data)
+	err := validateValue(buf, s.rootOp)
+	if err == nil && remainBytes(buf) > 0 {
+		err = errors.Errorf("%d trailing bytes after encoded data", remainBytes(buf))
+	}
+	if err != nil {
+		return errors.WithStack(&ValidationError{s.Name, buf.Offset(), err})
+	}
+	return nil
+}
+
+func validateValue(buf *ibuf.Buffer, opNode *operation) error {
+	switch opNode.handlerType {
+	case objectOpType:
+		for _, childNode := range opNode.children {
+			err := validateValue(buf, childNode)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case sliceOpType, arrayOpType:
+		length, err := readLength(buf)
+		if err != nil {
+			return errors.Wrapf(err, "property '%s'", opNode.displayName())
+		}
+
+		itemOp := opNode.children[0]
+		// fast path: all values of number types are valid
+		if isNumberType(itemOp.handlerType) {
+			return skipItems(buf, itemOp, length)
+		}
+		// every item takes one byte at least unless it's an empty object
+		if itemOp.fixedSize == 0 {
+			return nil
+		} else if length > uint64(remainBytes(buf)) {
+			return errors.Wrapf(ibuf.BufferOverreadError, "property '%s'", opNode.displayName())
+		}
+		for i := uint64(0); i < length; i++ {
+			err = validateValue(buf, itemOp)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case stringOpType:
+		length, err := readLength(buf)
+		if err != nil {
+			return errors.Wrapf(err, "property '%s'", opNode.displayName())
+		}
+		if length > uint64(remainBytes(buf)) {
+			return errors.Wrapf(ibuf.BufferOverreadError, "property '%s'", opNode.displayName())
+		}
+		if !utf8.Valid(buf.ReadBytes(int64(length))) {
+			return errors.Errorf("property '%s' is not a valid UTF-8 string", opNode.displayName())
+		}
+		return nil
+
+	case booleanOpType:
+		if remainBytes(buf) < 1 {
+			return errors.Wrapf(ibuf.BufferOverreadError, "property '%s'", opNode.displayName())
+		}
+		if d := buf.ReadByte(); d > 1 {
+			return errors.Errorf("property '%s' is not a valid boolean value: %d", opNode.displayName(), d)
+		}
+		return nil
+
+	default:
+		err := skipValue(buf, opNode)
+		if err != nil {
+			return errors.Wrapf(err, "property '%s'", opNode.displayName())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestValidate(t *testing.T) {
+	var err error
+	var expectErr *ValidationError
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"types", testdata.TypesExpData},
+		{"complex", testdata.ComplexExpData},
+		{"sliceObject", testdata.SliceExpData},
+		{"testStruct", testdata.StructExpData},
+	}
+	for _, test := range tests {
+		sch := jsonPack.GetSchema(test.name)
+		err = sch.Validate(test.data)
+		if err != nil {
+			t.Errorf("Validate %s fail, err: %+v", test.name, err)
+		}
+
+		// truncated data
+		err = sch.Validate(test.data[:len(test.data)-1])
+		if !errors.As(err, &expectErr) {
+			t.Errorf("Validate truncated %s data should fail, err: %v", test.name, err)
+		}
+
+		// trailing bytes
+		err = sch.Validate(append(append([]byte{}, test.data...), 0))
+		if !errors.As(err, &expectErr) {
+			t.Errorf("Validate %s data with trailing bytes should fail, err: %v", test.name, err)
+		}
+	}
+
+	sch, err := NewJSONPack().AddSchema("validate", SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"flag": {Type: "boolean"},
+			"name": {Type: "string"},
+		},
+		Order: []string{"flag", "name"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+
+	if err = sch.Validate([]byte{1, 2, 'o', 'k'}); err != nil {
+		t.Errorf("Validate fail, err: %+v", err)
+	}
+	if err = sch.Validate([]byte{2, 2, 'o', 'k'}); !errors.As(err, &expectErr) {
+		t.Errorf("Validate invalid boolean should fail, err: %v", err)
+	}
+	if err = sch.Validate([]byte{1, 2, 0xff, 0xfe}); !errors.As(err, &expectErr) {
+		t.Errorf("Validate invalid UTF-8 string should fail, err: %v", err)
+	}
+	if err = sch.Validate([]byte{1, 0xff, 0xff, 0xff, 0x7f}); !errors.As(err, &expectErr) {
+		t.Errorf("Validate invalid string length should fail, err: %v", err)
+	}
+}
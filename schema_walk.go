@@ -0,0 +1,101 @@
+package jsonpack
+
+import (
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+var errInvalidLength = errors.New("invalid length prefix")
+
+// maxEmptyItems is the maximum number of empty object items of array which can be expanded into
+// non-empty values, the length of such items isn't bounded by the size of encoded data.
+const maxEmptyItems = 1 << 20
+
+// remainBytes returns the number of bytes that not yet read in buf, it returns 0 if the offset
+// of buf is beyond the end, so the result can be converted to uint64 safely.
+func remainBytes(buf *ibuf.Buffer) int64 {
+	if n := buf.Capacity() - buf.Offset(); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// readLength reads a varuint length prefix of string or array from buf.
+func readLength(buf *ibuf.Buffer) (uint64, error) {
+	if remainBytes(buf) <= 0 {
+		return 0, errors.WithStack(ibuf.BufferOverreadError)
+	}
+	length, n := buf.ReadVarUint()
+	if n <= 0 {
+		return 0, errors.WithStack(errInvalidLength)
+	}
+	return length, nil
+}
+
+// skipBytes moves offset of buf forward n bytes, returns error if there is not enough bytes.
+func skipBytes(buf *ibuf.Buffer, n uint64) error {
+	if buf.Offset() > buf.Capacity() || n > uint64(remainBytes(buf)) {
+		return errors.WithStack(ibuf.BufferOverreadError)
+	}
+	buf.SeekUnsafe(int64(n), true)
+	return nil
+}
+
+// skipItems moves offset of buf forward past length items of array which described by itemOp.
+func skipItems(buf *ibuf.Buffer, itemOp *operation, length uint64) error {
+	// fast path: skip fixed size items at once
+	if itemOp.fixedSize == 0 {
+		return nil
+	} else if itemOp.fixedSize > 0 {
+		if length > uint64(remainBytes(buf))/uint64(itemOp.fixedSize) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		return skipBytes(buf, length*uint64(itemOp.fixedSize))
+	}
+
+	for i := uint64(0); i < length; i++ {
+		err := skipValue(buf, itemOp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipValue moves offset of buf forward past the encoded value which described by opNode.
+//
+// It checks the boundary of buf and returns error instead of panic if encoded data is truncated.
+func skipValue(buf *ibuf.Buffer, opNode *operation) error {
+	if opNode.fixedSize >= 0 {
+		return skipBytes(buf, uint64(opNode.fixedSize))
+	}
+
+	switch opNode.handlerType {
+	case objectOpType:
+		for _, childNode := range opNode.children {
+			err := skipValue(buf, childNode)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case sliceOpType, arrayOpType:
+		length, err := readLength(buf)
+		if err != nil {
+			return err
+		}
+		return skipItems(buf, opNode.children[0], length)
+
+	case stringOpType:
+		length, err := readLength(buf)
+		if err != nil {
+			return err
+		}
+		return skipBytes(buf, length)
+
+	default:
+		return errors.WithStack(&UnknownTypeError{opTypeName(opNode.handlerType)})
+	}
+}
@@ -0,0 +1,177 @@
+package jsonpack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+// SizeReport represents the encoded byte sizes attributed to each property of schema,
+// it's created by Schema.SizeReport and Schema.SizeReportBatch methods.
+type SizeReport struct {
+	// number of samples
+	Samples int
+	// total encoded bytes of all samples
+	TotalBytes int64
+	// sizes of properties, sorted by Bytes in descending order
+	Fields []*FieldSize
+}
+
+// FieldSize represents the encoded byte size of a property.
+type FieldSize struct {
+	// property path, the items of array are represented as "[]", likes "accounts[].name"
+	Path string
+	// type name of property in schema definition
+	Type string
+	// total encoded bytes of property in all samples, includes properties and items of object and array
+	Bytes int64
+	// total bytes of length prefixes of string and array
+	Overhead int64
+	// number of occurrences in all samples, the property of array items occurs once per item
+	Count int64
+	// average encoded bytes per sample
+	Average float64
+	// percentage of total encoded bytes of all samples
+	Percent float64
+}
+
+/*
+SizeReport encodes v and reports the encoded bytes attributed to each property,
+it's useful for finding out which properties dominate the encoded size, and where
+switching to smaller number types pays off.
+
+The valid types of v are the same as Encode method.
+
+Example:
+	report, err := sch.SizeReport(&info)
+	fmt.Println(report)
+*/
+func (s *Schema) SizeReport(v interface{}) (*SizeReport, error) {
+	return s.SizeReportBatch([]interface{}{v})
+}
+
+// SizeReportBatch encodes each of samples and reports the encoded bytes attributed to
+// each property over all samples.
+func (s *Schema) SizeReportBatch(samples []interface{}) (*SizeReport, error) {
+	c := sizeCollector{fields: make(map[string]*FieldSize)}
+	report := SizeReport{Samples: len(samples)}
+
+	for _, sample := range samples {
+		data, err := s.Encode(sample)
+		if err != nil {
+			return nil, err
+		}
+		report.TotalBytes += int64(len(data))
+
+		err = c.collect(ibuf.From(data), s.rootOp, "")
+		if err != nil {
+			return nil, errors.WithStack(&DecodeError{s.Name, err})
+		}
+	}
+
+	report.Fields = c.order
+	for _, field := range report.Fields {
+		if report.Samples > 0 {
+			field.Average = float64(field.Bytes) / float64(report.Samples)
+		}
+		if report.TotalBytes > 0 {
+			field.Percent = float64(field.Bytes) * 100 / float64(report.TotalBytes)
+		}
+	}
+	sort.SliceStable(report.Fields, func(i, j int) bool {
+		return report.Fields[i].Bytes > report.Fields[j].Bytes
+	})
+	return &report, nil
+}
+
+// String returns the report as a human-readable table.
+func (r *SizeReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "samples: %d, total bytes: %d\n", r.Samples, r.TotalBytes)
+
+	tw := tabwriter.NewWriter(&sb, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "PATH\tTYPE\tBYTES\tOVERHEAD\tCOUNT\tAVERAGE\tPERCENT\t")
+	for _, f := range r.Fields {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.2f\t%.2f%%\t\n", f.Path, f.Type, f.Bytes, f.Overhead, f.Count, f.Average, f.Percent)
+	}
+	tw.Flush()
+	return sb.String()
+}
+
+// sizeCollector collects encoded sizes of properties.
+type sizeCollector struct {
+	fields map[string]*FieldSize
+	// fields in the order of first occurrence
+	order []*FieldSize
+}
+
+// collect walks the encoded value of opNode and records its size and the sizes of its properties or items.
+func (c *sizeCollector) collect(buf *ibuf.Buffer, opNode *operation, path string) error {
+	var err error
+	var overhead int64
+
+	start := buf.Offset()
+	switch {
+	case opNode.handlerType == objectOpType:
+		for _, childNode := range opNode.children {
+			err = c.collect(buf, childNode, formatPath(path, pathElem{name: childNode.propName}))
+			if err != nil {
+				return err
+			}
+		}
+
+	case isArrayOp(opNode):
+		var length uint64
+		length, err = readLength(buf)
+		if err != nil {
+			return err
+		}
+		overhead = buf.Offset() - start
+
+		itemOp := opNode.children[0]
+		if itemOp.fixedSize != 0 && length > uint64(remainBytes(buf)) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		for i := uint64(0); i < length; i++ {
+			err = c.collect(buf, itemOp, path+"[]")
+			if err != nil {
+				return err
+			}
+		}
+
+	case opNode.handlerType == stringOpType:
+		var length uint64
+		length, err = readLength(buf)
+		if err != nil {
+			return err
+		}
+		overhead = buf.Offset() - start
+		err = skipBytes(buf, length)
+
+	default:
+		err = skipValue(buf, opNode)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the root value is the total bytes
+	if path == "" {
+		return nil
+	}
+	field, ok := c.fields[path]
+	if !ok {
+		field = &FieldSize{Path: path, Type: opTypeName(opNode.handlerType)}
+		c.fields[path] = field
+		c.order = append(c.order, field)
+	}
+	field.Bytes += buf.Offset() - start
+	field.Overhead += overhead
+	field.Count++
+	return nil
+}
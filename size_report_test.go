@@ -0,0 +1,62 @@
+package jsonpack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestSizeReport(t *testing.T) {
+	sch := jsonPack.GetSchema("sliceObject")
+	report, err := sch.SizeReport(testdata.SliceData)
+	if err != nil {
+		t.Fatalf("SizeReport fail, err: %+v", err)
+	}
+	if report.Samples != 1 || report.TotalBytes != int64(len(testdata.SliceExpData)) {
+		t.Errorf("SizeReport, expect total bytes: %d, got: %d", len(testdata.SliceExpData), report.TotalBytes)
+	}
+
+	expFields := map[string]FieldSize{
+		"[]":          {Type: "object", Bytes: 54, Count: 3},
+		"[].num2":     {Type: "float64be", Bytes: 24, Count: 3},
+		"[].num1":     {Type: "int32le", Bytes: 12, Count: 3},
+		"[].obj":      {Type: "object", Bytes: 18, Count: 3},
+		"[].obj.name": {Type: "string", Bytes: 18, Overhead: 3, Count: 3},
+	}
+	if len(report.Fields) != len(expFields) {
+		t.Fatalf("SizeReport, expect %d fields, got: %d", len(expFields), len(report.Fields))
+	}
+	for i, field := range report.Fields {
+		exp := expFields[field.Path]
+		if field.Type != exp.Type || field.Bytes != exp.Bytes || field.Overhead != exp.Overhead || field.Count != exp.Count {
+			t.Errorf("SizeReport field %s, expect: %+v, got: %+v", field.Path, exp, field)
+		}
+		if i > 0 && field.Bytes > report.Fields[i-1].Bytes {
+			t.Errorf("SizeReport fields should be sorted by bytes")
+		}
+	}
+	if report.Fields[0].Path != "[]" || report.Fields[0].Percent <= 98 {
+		t.Errorf("SizeReport, expect [] dominates, got: %+v", report.Fields[0])
+	}
+
+	// batch of samples
+	report, err = jsonPack.GetSchema("complex").SizeReportBatch([]interface{}{testdata.ComplexData, &testdata.ComplexStructData})
+	if err != nil {
+		t.Fatalf("SizeReportBatch fail, err: %+v", err)
+	}
+	if report.Samples != 2 || report.TotalBytes != int64(2*len(testdata.ComplexExpData)) {
+		t.Errorf("SizeReportBatch, expect total bytes: %d, got: %d", 2*len(testdata.ComplexExpData), report.TotalBytes)
+	}
+	for _, field := range report.Fields {
+		if field.Path == "category" && (field.Bytes != 8 || field.Average != 4 || field.Count != 2) {
+			t.Errorf("SizeReportBatch category fail, got: %+v", field)
+		}
+		if field.Path == "ips[]" && field.Count != 60 {
+			t.Errorf("SizeReportBatch ips[] fail, got: %+v", field)
+		}
+	}
+	if !strings.Contains(report.String(), "accounts[].currentStatus.msg") {
+		t.Errorf("SizeReport string fail, got: %s", report.String())
+	}
+}
@@ -27,6 +27,7 @@ var TypesAnyData interface{}
 var TypesMapData map[string]interface{}
 var TypesStructData Types
 var TypesExpData []byte
+var TypesGoldenData []byte
 
 var SliceSchDef []byte
 var SliceRawData []byte
@@ -71,6 +72,11 @@ func init() {
 		os.Exit(1)
 	}
 
+	TypesGoldenData, err = loadRawTestData("types.golden.json")
+	if err != nil {
+		os.Exit(1)
+	}
+
 	ComplexSchDef, _, err = loadJsonTextData("complex.def.json")
 	if err != nil {
 		os.Exit(1)
@@ -0,0 +1,230 @@
+package jsonpack
+
+import (
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+Transcode converts data which encoded by schema from into encoded data of schema to,
+without decoding data into map or struct.
+
+It walks the schema definitions of both schemas in tandem and copies values between
+buffers directly, the following changes between schemas are supported:
+
+* Copying: the property has the same type in both schemas.
+
+* Widening and narrowing: the property has different number types in both schemas, likes "int16le" to "int32le",
+or "uint32le" to "uint32be". It returns error if the value overflows the number type of schema to.
+
+* Re-ordering: the order of properties are different in both schemas.
+
+* Dropping: the property exists in schema from but not in schema to.
+
+* Defaulting: the property exists in schema to but not in schema from, the zero value of type
+will be written, which is 0 for number types, false for boolean type, empty string for string type,
+empty array for array type, and object with default values of properties for object type.
+
+It returns *TranscodeError error if the type of property changes between non-number types,
+likes "string" to "int32le", or the encoded data is invalid.
+
+Example of upgrading data from version 1 to version 2 of schema:
+	schV1 := jsonPack.GetSchemaVersion("Info", 1)
+	schV2 := jsonPack.GetSchemaVersion("Info", 2)
+	v2Data, err := jsonpack.Transcode(schV1, schV2, v1Data)
+*/
+func Transcode(from, to *Schema, data []byte) (result []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case string:
+				err = errors.WithStack(&TranscodeError{from.Name, to.Name, errors.New(r)})
+			case error:
+				err = errors.WithStack(&TranscodeError{from.Name, to.Name, r})
+			}
+		}
+	}()
+
+	src := ibuf.From(data)
+	dst := ibuf.From(make([]byte, maxInt64(to.encodeBufSize, int64(len(data)))))
+	t := transcoder{}
+	err = t.transcode(src, dst, from.rootOp, to.rootOp)
+	if err != nil {
+		return nil, errors.WithStack(&TranscodeError{from.Name, to.Name, err})
+	}
+	return dst.Seal(), nil
+}
+
+// transcoder holds the states of transcoding procedure.
+type transcoder struct {
+	// offsets is a stack of value offsets of object properties in source buffer
+	offsets []int64
+}
+
+func (t *transcoder) transcode(src *ibuf.Buffer, dst *ibuf.Buffer, fromOp *operation, toOp *operation) error {
+	switch {
+	case fromOp.handlerType == objectOpType && toOp.handlerType == objectOpType:
+		return t.transcodeObject(src, dst, fromOp, toOp)
+
+	case isArrayOp(fromOp) && isArrayOp(toOp):
+		length, err := readLength(src)
+		if err != nil {
+			return err
+		}
+		dst.WriteVarUint(length)
+
+		fromItem, toItem := fromOp.children[0], toOp.children[0]
+		// fast path: copy items of the same builtin type at once
+		if fromItem.handlerType == toItem.handlerType && fromItem.handlerType != objectOpType && fromItem.fixedSize > 0 {
+			if length > uint64(remainBytes(src))/uint64(fromItem.fixedSize) {
+				return errors.WithStack(ibuf.BufferOverreadError)
+			}
+			dst.WriteBytes(src.ReadBytes(int64(length) * fromItem.fixedSize))
+			return nil
+		}
+
+		// every item takes one byte at least unless it's an empty object
+		switch {
+		case fromItem.fixedSize != 0:
+			if length > uint64(remainBytes(src)) {
+				return errors.WithStack(ibuf.BufferOverreadError)
+			}
+		case toItem.fixedSize == 0:
+			// empty objects to empty objects, there is nothing to read or write
+			return nil
+		case length > maxEmptyItems:
+			return errors.Errorf("%d empty items exceed the limit %d of expanding into non-empty items", length, maxEmptyItems)
+		}
+		for i := uint64(0); i < length; i++ {
+			err = t.transcode(src, dst, fromItem, toItem)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case fromOp.handlerType == stringOpType && toOp.handlerType == stringOpType:
+		length, err := readLength(src)
+		if err != nil {
+			return err
+		}
+		if length > uint64(remainBytes(src)) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		dst.WriteVarUint(length)
+		dst.WriteBytes(src.ReadBytes(int64(length)))
+		return nil
+
+	case fromOp.handlerType == toOp.handlerType && fromOp.fixedSize > 0:
+		// same builtin type, copy bytes directly
+		if fromOp.fixedSize > remainBytes(src) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		dst.WriteBytes(src.ReadBytes(fromOp.fixedSize))
+		return nil
+
+	case isNumberType(fromOp.handlerType) && isNumberType(toOp.handlerType):
+		if fromOp.fixedSize > remainBytes(src) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		err := writeNumber(dst, toOp.handlerType, readNumber(src, fromOp.handlerType))
+		if err != nil {
+			return errors.Wrapf(err, "property '%s'", toOp.displayName())
+		}
+		return nil
+
+	default:
+		return errors.Errorf("property '%s' can't be converted from %s type to %s type",
+			toOp.displayName(), opTypeName(fromOp.handlerType), opTypeName(toOp.handlerType))
+	}
+}
+
+func (t *transcoder) transcodeObject(src *ibuf.Buffer, dst *ibuf.Buffer, fromOp *operation, toOp *operation) error {
+	var err error
+
+	// fast path: both objects have the same properties in the same order, transcode properties sequentially
+	if sameProperties(fromOp, toOp) {
+		for i, toChild := range toOp.children {
+			err = t.transcode(src, dst, fromOp.children[i], toChild)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// index offsets of properties in source buffer
+	base := len(t.offsets)
+	for _, fromChild := range fromOp.children {
+		t.offsets = append(t.offsets, src.Offset())
+		err = skipValue(src, fromChild)
+		if err != nil {
+			return err
+		}
+	}
+	end := src.Offset()
+
+	for _, toChild := range toOp.children {
+		idx := findProperty(fromOp, toChild.propName)
+		if idx < 0 {
+			writeDefault(dst, toChild)
+			continue
+		}
+		src.SeekUnsafe(t.offsets[base+idx], false)
+		err = t.transcode(src, dst, fromOp.children[idx], toChild)
+		if err != nil {
+			return err
+		}
+	}
+
+	src.SeekUnsafe(end, false)
+	t.offsets = t.offsets[:base]
+	return nil
+}
+
+// writeDefault writes zero value of opNode into buf.
+func writeDefault(buf *ibuf.Buffer, opNode *operation) {
+	switch opNode.handlerType {
+	case objectOpType:
+		for _, childNode := range opNode.children {
+			writeDefault(buf, childNode)
+		}
+	case sliceOpType, arrayOpType, stringOpType:
+		buf.WriteVarUint(0)
+	default:
+		// zero value of all builtin fixed size types are zero bytes
+		for i := int64(0); i < opNode.fixedSize; i++ {
+			buf.WriteByte(0)
+		}
+	}
+}
+
+// isArrayOp reports whether opNode is an array operation.
+func isArrayOp(opNode *operation) bool {
+	return opNode.handlerType == sliceOpType || opNode.handlerType == arrayOpType
+}
+
+// findProperty returns the index of property in children of object operation,
+// or returns -1 if not found.
+func findProperty(opNode *operation, propName string) int {
+	for i, childNode := range opNode.children {
+		if childNode.propName == propName {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameProperties reports whether both object operations have the same properties in the same order.
+func sameProperties(a *operation, b *operation) bool {
+	if len(a.children) != len(b.children) {
+		return false
+	}
+	for i, childNode := range a.children {
+		if childNode.propName != b.children[i].propName {
+			return false
+		}
+	}
+	return true
+}
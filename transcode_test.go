@@ -0,0 +1,163 @@
+package jsonpack
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var transcodeSchV1 = SchemaDef{
+	Type: "object",
+	Properties: map[string]*SchemaDef{
+		"id":   {Type: "int16le"},
+		"name": {Type: "string"},
+		"tags": {Type: "array", Items: &SchemaDef{Type: "string"}},
+		"pos": {
+			Type: "object",
+			Properties: map[string]*SchemaDef{
+				"x": {Type: "int16le"},
+				"y": {Type: "int16le"},
+			},
+			Order: []string{"x", "y"},
+		},
+	},
+	Order: []string{"id", "name", "tags", "pos"},
+}
+
+var transcodeSchV2 = SchemaDef{
+	Type: "object",
+	Properties: map[string]*SchemaDef{
+		"name": {Type: "string"},
+		"id":   {Type: "int32le"},
+		"pos": {
+			Type: "object",
+			Properties: map[string]*SchemaDef{
+				"x": {Type: "int64be"},
+				"y": {Type: "float64le"},
+				"z": {Type: "uint8"},
+			},
+			Order: []string{"y", "x", "z"},
+		},
+		"extra": {Type: "string"},
+		"items": {Type: "array", Items: &SchemaDef{Type: "uint8"}},
+	},
+	Order: []string{"name", "id", "pos", "extra", "items"},
+}
+
+func TestTranscode(t *testing.T) {
+	jsonPacker := NewJSONPack()
+	schV1, err := jsonPacker.AddSchemaVersion("transcode", 1, transcodeSchV1)
+	if err != nil {
+		t.Fatalf("AddSchemaVersion v1 fail, err: %+v", err)
+	}
+	schV2, err := jsonPacker.AddSchemaVersion("transcode", 2, transcodeSchV2)
+	if err != nil {
+		t.Fatalf("AddSchemaVersion v2 fail, err: %+v", err)
+	}
+
+	v1Data, err := schV1.Encode(map[string]interface{}{
+		"id":   int16(-300),
+		"name": "transcode",
+		"tags": []interface{}{"a", "b"},
+		"pos":  map[string]interface{}{"x": int16(10), "y": int16(-20)},
+	})
+	if err != nil {
+		t.Fatalf("Encode v1 fail, err: %+v", err)
+	}
+
+	v2Data, err := Transcode(schV1, schV2, v1Data)
+	if err != nil {
+		t.Fatalf("Transcode fail, err: %+v", err)
+	}
+
+	expData, err := schV2.Encode(map[string]interface{}{
+		"name":  "transcode",
+		"id":    int32(-300),
+		"pos":   map[string]interface{}{"x": int64(10), "y": float64(-20), "z": uint8(0)},
+		"extra": "",
+		"items": []interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("Encode v2 fail, err: %+v", err)
+	}
+	if !compareBytes(t, v2Data, expData) {
+		t.Errorf("Transcoded data mismatch")
+	}
+
+	// narrowing to single byte types
+	schByte, err := jsonPacker.AddSchemaVersion("transcodeByte", 1, SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"pos": {Type: "object", Properties: map[string]*SchemaDef{"x": {Type: "uint8"}, "y": {Type: "int8"}}, Order: []string{"x", "y"}}},
+		Order:      []string{"pos"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchemaVersion with byte types fail, err: %+v", err)
+	}
+	byteData, err := Transcode(schV1, schByte, v1Data)
+	if err != nil {
+		t.Fatalf("Transcode to byte types fail, err: %+v", err)
+	}
+	if !compareBytes(t, byteData, []byte{10, 0xec}) {
+		t.Errorf("Transcoded data of byte types mismatch")
+	}
+
+	// narrowing with overflow value
+	v2Data, err = schV2.Encode(map[string]interface{}{
+		"name":  "overflow",
+		"id":    int32(100000),
+		"pos":   map[string]interface{}{"x": int64(1), "y": float64(2), "z": uint8(3)},
+		"extra": "extra",
+		"items": []interface{}{uint8(1)},
+	})
+	if err != nil {
+		t.Fatalf("Encode v2 fail, err: %+v", err)
+	}
+	_, err = Transcode(schV2, schV1, v2Data)
+	var expectErr *TranscodeError
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Transcode should return TranscodeError, err: %v", err)
+	}
+
+	// incompatible type
+	schStr, err := jsonPacker.AddSchemaVersion("transcode", 3, SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"id": {Type: "string"}},
+		Order:      []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchemaVersion v3 fail, err: %+v", err)
+	}
+	_, err = Transcode(schV1, schStr, v1Data)
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Transcode should return TranscodeError, err: %v", err)
+	}
+
+	// truncated data
+	_, err = Transcode(schV1, schV2, v1Data[:len(v1Data)-1])
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Transcode should return TranscodeError, err: %v", err)
+	}
+
+	// a huge number of empty object items
+	emptyData := []byte{0xff, 0xff, 0xff, 0xff, 0x0f}
+	schEmpty, err := jsonPacker.AddSchemaVersion("emptyItems", 1, `{"type": "array", "items": {"type": "object", "properties": {}, "order": []}}`)
+	if err != nil {
+		t.Fatalf("AddSchemaVersion empty items fail, err: %+v", err)
+	}
+	result, err := Transcode(schEmpty, schEmpty, emptyData)
+	if err != nil || !bytes.Equal(result, emptyData) {
+		t.Errorf("Transcode empty items fail, got: %v, err: %+v", result, err)
+	}
+	schItems, err := jsonPacker.AddSchemaVersion("emptyItems", 2, SchemaDef{
+		Type:  "array",
+		Items: &SchemaDef{Type: "object", Properties: map[string]*SchemaDef{"id": {Type: "uint32le"}}, Order: []string{"id"}},
+	})
+	if err != nil {
+		t.Fatalf("AddSchemaVersion non-empty items fail, err: %+v", err)
+	}
+	_, err = Transcode(schEmpty, schItems, emptyData)
+	if !errors.As(err, &expectErr) {
+		t.Errorf("Transcode huge number of empty items should fail, err: %v", err)
+	}
+}
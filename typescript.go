@@ -0,0 +1,206 @@
+package jsonpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// reserved words which can't be used as type names of TypeScript
+var tsReservedWords = map[string]bool{
+	"any": true, "bigint": true, "boolean": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "export": true, "extends": true, "false": true, "finally": true,
+	"for": true, "function": true, "if": true, "import": true, "in": true, "instanceof": true,
+	"never": true, "new": true, "null": true, "number": true, "object": true, "return": true,
+	"string": true, "super": true, "switch": true, "symbol": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "undefined": true, "unknown": true, "var": true,
+	"void": true, "while": true, "with": true,
+}
+
+/*
+ToTypeScript converts schema definition def to TypeScript module which declares the types of values
+and registers the equivalent schema of Buffer Plus https://github.com/arloliu/buffer-plus, name is
+the name of top-level type and the registered schema.
+
+The object types are converted to interfaces, the nested interfaces are named by name followed by
+the upper camel case of property names, likes "InfoAddress" for "address" property of "Info" type.
+The optional properties are declared as optional members, the array types are converted to arrays,
+and all number types are converted to number type, so the values of 64-bit integer types beyond
+Number.MAX_SAFE_INTEGER lose precision in JavaScript.
+
+The registered schema has the same encoding format as def, the type names are canonical, likes
+"boolean" and "float64le", and the properties which don't exist in "order" are omitted.
+
+It returns *ConvertError error with the property path if def is invalid, likes the property in order
+doesn't exist or the type is unknown.
+
+Example output of ToTypeScript(schDef, "Info"):
+	import BufferPlus from 'buffer-plus';
+
+	export interface Info {
+	  name: string;
+	  area?: number;
+	  address: InfoAddress;
+	}
+
+	export interface InfoAddress {
+	  street: string;
+	}
+
+	export const InfoSchema = {
+	  "type": "object",
+	  "properties": {
+	    "address": {
+	      ...
+	    },
+	    ...
+	  },
+	  "order": [
+	    "name",
+	    "area",
+	    "address"
+	  ]
+	};
+
+	BufferPlus.createSchema('Info', InfoSchema);
+*/
+func ToTypeScript(def *SchemaDef, name string) ([]byte, error) {
+	if !isTSIdent(name) || tsReservedWords[name] {
+		return nil, errors.WithStack(&ConvertError{"", errors.Errorf("'%s' is not a valid TypeScript type name", name)})
+	}
+
+	w := tsWriter{names: make(map[string]bool)}
+	if def != nil && strings.ToLower(def.Type) == "object" {
+		_, err := w.tsType(def, name, "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// the interface of array items is named with "Item" suffix, e.g. type Infos = InfosItem[]
+		w.names[name] = true
+		typ, err := w.tsType(def, name+"Item", "")
+		if err != nil {
+			return nil, err
+		}
+		w.decls = append([]string{fmt.Sprintf("export type %s = %s;\n", name, typ)}, w.decls...)
+	}
+
+	schema, err := json.MarshalIndent(canonicalSchemaDef(def), "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+
+	var b bytes.Buffer
+	b.WriteString("import BufferPlus from 'buffer-plus';\n")
+	for _, decl := range w.decls {
+		b.WriteByte('\n')
+		b.WriteString(decl)
+	}
+	fmt.Fprintf(&b, "\nexport const %sSchema = %s;\n", name, schema)
+	fmt.Fprintf(&b, "\nBufferPlus.createSchema(%s, %sSchema);\n", tsString(name), name)
+	return b.Bytes(), nil
+}
+
+// tsWriter collects TypeScript type declarations of schema definition.
+type tsWriter struct {
+	decls []string
+	// declared type names
+	names map[string]bool
+}
+
+// tsType returns TypeScript type of def, and declares interface if def is object type, the interface
+// is named by name, or name followed by a number if name has been used.
+func (w *tsWriter) tsType(def *SchemaDef, name string, path string) (string, error) {
+	if def == nil {
+		return "", errors.WithStack(&ConvertError{path, errors.New("schema definition is nil")})
+	}
+
+	typ := strings.ToLower(def.Type)
+	switch typ {
+	case "object":
+		name = uniqueName(name, w.names)
+		w.names[name] = true
+		// reserves the position of declaration, nested interfaces are declared after it
+		idx := len(w.decls)
+		w.decls = append(w.decls, "")
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "export interface %s {\n", name)
+		for _, propName := range def.Order {
+			propPath := formatPath(path, pathElem{name: propName})
+			prop, ok := def.Properties[propName]
+			if !ok {
+				return "", errors.WithStack(&ConvertError{propPath, errors.New("property in order doesn't exist")})
+			}
+
+			propType, err := w.tsType(prop, name+upperCamelName(propName), propPath)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString("  ")
+			if isTSIdent(propName) {
+				b.WriteString(propName)
+			} else {
+				b.WriteString(tsString(propName))
+			}
+			if prop.Optional {
+				b.WriteByte('?')
+			}
+			fmt.Fprintf(&b, ": %s;\n", propType)
+		}
+		b.WriteString("}\n")
+		w.decls[idx] = b.String()
+		return name, nil
+
+	case "array":
+		itemType, err := w.tsType(def.Items, name, path+"[]")
+		if err != nil {
+			return "", err
+		}
+		return itemType + "[]", nil
+
+	default:
+		opType, ok := builtinOpHandlerTypes[typ]
+		if !ok {
+			return "", errors.WithStack(&ConvertError{path, &UnknownTypeError{def.Type}})
+		}
+		switch opType {
+		case stringOpType:
+			return "string", nil
+		case booleanOpType:
+			return "boolean", nil
+		default:
+			return "number", nil
+		}
+	}
+}
+
+// tsString returns single-quoted string literal of TypeScript.
+func tsString(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q[1:len(q)-1], `\"`, `"`)
+	return "'" + strings.ReplaceAll(q, "'", `\'`) + "'"
+}
+
+// isTSIdent reports whether s is an identifier of TypeScript which contains ASCII characters only.
+func isTSIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', c == '$', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
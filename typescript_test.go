@@ -0,0 +1,153 @@
+package jsonpack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestToTypeScript(t *testing.T) {
+	schDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":      {Type: "string"},
+			"area":      {Type: "uint32le", Optional: true},
+			"active":    {Type: "bool"},
+			"user-id":   {Type: "UInt64BE"},
+			"tags":      {Type: "array", Items: &SchemaDef{Type: "string"}},
+			"unordered": {Type: "int8"},
+			"address": {
+				Type:       "object",
+				Properties: map[string]*SchemaDef{"street": {Type: "string"}},
+				Order:      []string{"street"},
+			},
+		},
+		Order: []string{"name", "area", "active", "user-id", "tags", "address"},
+	}
+
+	src, err := ToTypeScript(schDef, "Info")
+	if err != nil {
+		t.Fatalf("ToTypeScript fail, err: %+v", err)
+	}
+	expSrc := strings.Join([]string{
+		"import BufferPlus from 'buffer-plus';",
+		"",
+		"export interface Info {",
+		"  name: string;",
+		"  area?: number;",
+		"  active: boolean;",
+		"  'user-id': number;",
+		"  tags: string[];",
+		"  address: InfoAddress;",
+		"}",
+		"",
+		"export interface InfoAddress {",
+		"  street: string;",
+		"}",
+		"",
+		"export const InfoSchema = {",
+		`  "type": "object",`,
+		`  "properties": {`,
+		`    "active": {`,
+		`      "type": "boolean"`,
+		`    },`,
+		`    "address": {`,
+		`      "type": "object",`,
+		`      "properties": {`,
+		`        "street": {`,
+		`          "type": "string"`,
+		`        }`,
+		`      },`,
+		`      "order": [`,
+		`        "street"`,
+		`      ]`,
+		`    },`,
+		`    "area": {`,
+		`      "type": "uint32le"`,
+		`    },`,
+		`    "name": {`,
+		`      "type": "string"`,
+		`    },`,
+		`    "tags": {`,
+		`      "type": "array",`,
+		`      "items": {`,
+		`        "type": "string"`,
+		`      }`,
+		`    },`,
+		`    "user-id": {`,
+		`      "type": "uint64be"`,
+		`    }`,
+		`  },`,
+		`  "order": [`,
+		`    "name",`,
+		`    "area",`,
+		`    "active",`,
+		`    "user-id",`,
+		`    "tags",`,
+		`    "address"`,
+		`  ]`,
+		"};",
+		"",
+		"BufferPlus.createSchema('Info', InfoSchema);",
+		"",
+	}, "\n")
+	if string(src) != expSrc {
+		t.Errorf("ToTypeScript result:\n%s\nexpect:\n%s", src, expSrc)
+	}
+
+	src, err = ToTypeScript(&SchemaDef{Type: "array", Items: &SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"id": {Type: "int16be"}},
+		Order:      []string{"id"},
+	}}, "Infos")
+	if err != nil {
+		t.Fatalf("ToTypeScript with array fail, err: %+v", err)
+	}
+	expPrefix := strings.Join([]string{
+		"import BufferPlus from 'buffer-plus';",
+		"",
+		"export type Infos = InfosItem[];",
+		"",
+		"export interface InfosItem {",
+		"  id: number;",
+		"}",
+		"",
+		"export const InfosSchema = {",
+		`  "type": "array",`,
+		"",
+	}, "\n")
+	if !strings.HasPrefix(string(src), expPrefix) {
+		t.Errorf("ToTypeScript with array result:\n%s\nexpect prefix:\n%s", src, expPrefix)
+	}
+}
+
+func TestToTypeScriptError(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      *SchemaDef
+		typeName string
+		path     string
+	}{
+		{"invalid type name", &SchemaDef{Type: "string"}, "my-type", ""},
+		{"reserved type name", &SchemaDef{Type: "string"}, "string", ""},
+		{"unknown type", &SchemaDef{
+			Type:       "object",
+			Properties: map[string]*SchemaDef{"a": {Type: "array", Items: &SchemaDef{Type: "double"}}},
+			Order:      []string{"a"},
+		}, "Info", "a[]"},
+		{"missing property", &SchemaDef{Type: "object", Properties: map[string]*SchemaDef{}, Order: []string{"a"}}, "Info", "a"},
+		{"missing items", &SchemaDef{Type: "array"}, "Info", "[]"},
+	}
+	for _, test := range tests {
+		_, err := ToTypeScript(test.def, test.typeName)
+		var convertErr *ConvertError
+		if !errors.As(err, &convertErr) {
+			t.Errorf("%s: ToTypeScript should return ConvertError, got: %v", test.name, err)
+			continue
+		}
+		if convertErr.Path != test.path {
+			t.Errorf("%s: path of ConvertError: '%s', expect: '%s'", test.name, convertErr.Path, test.path)
+		}
+	}
+}
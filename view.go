@@ -0,0 +1,330 @@
+package jsonpack
+
+import (
+	"github.com/pkg/errors"
+
+	ibuf "github.com/arloliu/jsonpack/buffer"
+)
+
+/*
+View is a read-only view over encoded data, it allows traversing encoded data
+like a document without decoding it.
+
+The offsets of object properties and array items are indexed on first access
+and reused by later accesses, the items of array which have fixed size are located
+directly without indexing.
+
+Field and Index methods return a view of the property or item, the error
+that occurs in traversing is kept in the returned view and reported by the value
+methods, so the calls can be chained.
+
+The string and byte slice returned by view share the underlying memory with
+the encoded data, the encoded data should not be modified while the view is in use.
+
+A View is not safe for concurrent use, because the offsets are indexed lazily,
+each goroutine should create its own view by View method.
+
+Example:
+	view := sch.View(encodedData)
+	sku, err := view.Field("items").Index(2).Field("sku").String()
+*/
+type View struct {
+	schema *Schema
+	data   []byte
+	opNode *operation
+	// start offset of encoded value
+	offset int64
+	parent *View
+	elem   pathElem
+	err    error
+
+	indexed bool
+	// number of items if the view is an array
+	length uint64
+	// offsets of properties of object or items of array that have been indexed
+	offsets []int64
+}
+
+// View returns a read-only view over encoded data.
+func (s *Schema) View(data []byte) *View {
+	return &View{schema: s, data: data, opNode: s.rootOp}
+}
+
+// Err returns the error that occurs in traversing to the view.
+func (v *View) Err() error {
+	return v.err
+}
+
+// Path returns the property path of the view, likes "items[2].sku".
+func (v *View) Path() string {
+	if v.parent == nil {
+		return ""
+	}
+	return formatPath(v.parent.Path(), v.elem)
+}
+
+// Type returns type name of the view in schema definition, likes "object", "array" or "uint32le".
+func (v *View) Type() string {
+	if v.err != nil {
+		return "null"
+	}
+	return opTypeName(v.opNode.handlerType)
+}
+
+// Field returns a view of the property of object.
+func (v *View) Field(name string) *View {
+	elem := pathElem{name: name}
+	if v.err != nil {
+		return v.child(elem, nil, 0, v.err)
+	}
+	if v.opNode.handlerType != objectOpType {
+		return v.child(elem, nil, 0, errors.Errorf("'%s' is not an object", v.Path()))
+	}
+
+	idx := findProperty(v.opNode, name)
+	if idx < 0 {
+		return v.child(elem, nil, 0, errors.Errorf("property '%s' doesn't exist", formatPath(v.Path(), elem)))
+	}
+	offset, err := v.childOffset(idx)
+	return v.child(elem, v.opNode.children[idx], offset, err)
+}
+
+// Index returns a view of the i-th item of array.
+func (v *View) Index(i int) *View {
+	elem := pathElem{index: uint64(i), isIndex: true}
+	if v.err != nil {
+		return v.child(elem, nil, 0, v.err)
+	}
+	if !isArrayOp(v.opNode) {
+		return v.child(elem, nil, 0, errors.Errorf("'%s' is not an array", v.Path()))
+	}
+
+	err := v.index()
+	if err != nil {
+		return v.child(elem, nil, 0, err)
+	}
+	if i < 0 || uint64(i) >= v.length {
+		return v.child(elem, nil, 0, errors.Errorf("index %d of '%s' out of range, length: %d", i, v.Path(), v.length))
+	}
+	offset, err := v.childOffset(i)
+	return v.child(elem, v.opNode.children[0], offset, err)
+}
+
+// Len returns the number of items of array, the number of properties of object,
+// or the byte length of string.
+func (v *View) Len() (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	switch {
+	case isArrayOp(v.opNode):
+		err := v.index()
+		if err != nil {
+			return 0, v.pathError(err)
+		}
+		return int(v.length), nil
+	case v.opNode.handlerType == objectOpType:
+		return len(v.opNode.children), nil
+	case v.opNode.handlerType == stringOpType:
+		buf := v.buffer()
+		length, err := readLength(buf)
+		if err != nil {
+			return 0, v.pathError(err)
+		}
+		if length > uint64(remainBytes(buf)) {
+			return 0, v.pathError(errors.WithStack(ibuf.BufferOverreadError))
+		}
+		return int(length), nil
+	default:
+		return 0, errors.WithStack(&WrongTypeError{opTypeName(v.opNode.handlerType)})
+	}
+}
+
+// String returns the string value of the view, the returned string shares the
+// underlying memory with the encoded data.
+func (v *View) String() (string, error) {
+	if v.err != nil {
+		return "", v.err
+	}
+	val, err := readStringValue(v.buffer(), v.opNode)
+	if err != nil {
+		return "", v.readError(err)
+	}
+	return val, nil
+}
+
+// Bool returns the boolean value of the view.
+func (v *View) Bool() (bool, error) {
+	if v.err != nil {
+		return false, v.err
+	}
+	val, err := readBoolValue(v.buffer(), v.opNode)
+	if err != nil {
+		return false, v.readError(err)
+	}
+	return val, nil
+}
+
+// Int returns the number value of the view as int64.
+func (v *View) Int() (int64, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	val, err := readInt64Value(v.buffer(), v.opNode)
+	if err != nil {
+		return 0, v.readError(err)
+	}
+	return val, nil
+}
+
+// Uint returns the number value of the view as uint64.
+func (v *View) Uint() (uint64, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	val, err := readUint64Value(v.buffer(), v.opNode)
+	if err != nil {
+		return 0, v.readError(err)
+	}
+	return val, nil
+}
+
+// Float returns the number value of the view as float64.
+func (v *View) Float() (float64, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	val, err := readFloat64Value(v.buffer(), v.opNode)
+	if err != nil {
+		return 0, v.readError(err)
+	}
+	return val, nil
+}
+
+// Bytes returns the encoded bytes of the view, the returned slice shares the
+// underlying memory with the encoded data.
+func (v *View) Bytes() ([]byte, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	buf := v.buffer()
+	err := skipValue(buf, v.opNode)
+	if err != nil {
+		return nil, v.pathError(err)
+	}
+	return v.data[v.offset:buf.Offset():buf.Offset()], nil
+}
+
+// Interface decodes the value of the view, the type of return value is the
+// same as Schema.Get method.
+func (v *View) Interface() (interface{}, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	val, err := decodeValue(v.buffer(), v.opNode)
+	if err != nil {
+		return nil, errors.WithStack(&DecodeError{v.schema.Name, err})
+	}
+	return val, nil
+}
+
+// child returns a view of property or item of the view.
+func (v *View) child(elem pathElem, opNode *operation, offset int64, err error) *View {
+	c := &View{
+		schema: v.schema,
+		data:   v.data,
+		opNode: opNode,
+		offset: offset,
+		parent: v,
+		elem:   elem,
+	}
+	if err != nil {
+		// keeps the error from ancestor as it is
+		if err == v.err {
+			c.err = err
+		} else {
+			c.err = errors.WithStack(&PathError{v.schema.Name, c.Path(), err})
+		}
+	}
+	return c
+}
+
+// buffer returns a buffer which offset is at the start of encoded value of the view.
+func (v *View) buffer() *ibuf.Buffer {
+	buf := ibuf.From(v.data)
+	buf.SeekUnsafe(v.offset, false)
+	return buf
+}
+
+// index reads the length of array and prepares offset index of the view.
+func (v *View) index() error {
+	if v.indexed {
+		return nil
+	}
+
+	buf := v.buffer()
+	if isArrayOp(v.opNode) {
+		length, err := readLength(buf)
+		if err != nil {
+			return err
+		}
+		// every item takes fixed size or one byte at least unless it's an empty object
+		itemOp := v.opNode.children[0]
+		remain := uint64(remainBytes(buf))
+		if (itemOp.fixedSize > 0 && length > remain/uint64(itemOp.fixedSize)) ||
+			(itemOp.fixedSize < 0 && length > remain) {
+			return errors.WithStack(ibuf.BufferOverreadError)
+		}
+		v.length = length
+	}
+	v.offsets = []int64{buf.Offset()}
+	v.indexed = true
+	return nil
+}
+
+// childOffset returns the offset of idx-th property of object or item of array, the values before
+// the property or item will be skipped and their offsets will be indexed.
+func (v *View) childOffset(idx int) (int64, error) {
+	err := v.index()
+	if err != nil {
+		return 0, err
+	}
+
+	isArray := isArrayOp(v.opNode)
+	if isArray && v.opNode.children[0].fixedSize >= 0 {
+		return v.offsets[0] + int64(idx)*v.opNode.children[0].fixedSize, nil
+	}
+	if idx < len(v.offsets) {
+		return v.offsets[idx], nil
+	}
+
+	buf := ibuf.From(v.data)
+	buf.SeekUnsafe(v.offsets[len(v.offsets)-1], false)
+	for len(v.offsets) <= idx {
+		childOp := v.opNode.children[0]
+		if !isArray {
+			childOp = v.opNode.children[len(v.offsets)-1]
+		}
+		err = skipValue(buf, childOp)
+		if err != nil {
+			return 0, err
+		}
+		v.offsets = append(v.offsets, buf.Offset())
+	}
+	return v.offsets[idx], nil
+}
+
+// pathError wraps err into PathError with the path of view.
+func (v *View) pathError(err error) error {
+	return errors.WithStack(&PathError{v.schema.Name, v.Path(), err})
+}
+
+// readError converts error of reading value into PathError, except WrongTypeError
+// which is returned as it is.
+func (v *View) readError(err error) error {
+	var typeErr *WrongTypeError
+	if errors.As(err, &typeErr) {
+		return err
+	}
+	return v.pathError(err)
+}
@@ -0,0 +1,113 @@
+package jsonpack
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestView(t *testing.T) {
+	var err error
+	var expectErr *PathError
+
+	view := jsonPack.GetSchema("complex").View(testdata.ComplexExpData)
+
+	category, err := view.Field("category").Uint()
+	if err != nil || category != 1 {
+		t.Errorf("View category fail, got: %d, err: %+v", category, err)
+	}
+
+	n, err := view.Field("ips").Len()
+	if err != nil || n != 30 {
+		t.Errorf("View ips length fail, got: %d, err: %+v", n, err)
+	}
+	ips := view.Field("ips")
+	// access items out of order to exercise the offset index
+	for _, i := range []int{5, 2, 29, 0} {
+		ip, err := ips.Index(i).String()
+		if err != nil || ip != testdata.ComplexStructData.Ips[i] {
+			t.Errorf("View ips[%d] fail, got: %s, err: %+v", i, ip, err)
+		}
+	}
+
+	pos, err := view.Field("positions").Index(3).Int()
+	if err != nil || pos != 201 {
+		t.Errorf("View positions[3] fail, got: %d, err: %+v", pos, err)
+	}
+
+	accounts := view.Field("accounts")
+	for i, acct := range testdata.ComplexStructData.Accounts {
+		msg, err := accounts.Index(i).Field("currentStatus").Field("msg").String()
+		if err != nil || msg != acct.CurrentStatus.Msg {
+			t.Errorf("View accounts[%d].currentStatus.msg fail, got: %s, err: %+v", i, msg, err)
+		}
+		email, err := accounts.Index(i).Field("email").String()
+		if err != nil || email != acct.Email {
+			t.Errorf("View accounts[%d].email fail, got: %s, err: %+v", i, email, err)
+		}
+	}
+
+	user, err := view.Field("user").Interface()
+	if err != nil {
+		t.Fatalf("View user fail, err: %+v", err)
+	}
+	if user.(map[string]interface{})["email"] != "test@example.com" {
+		t.Errorf("View user fail, got: %v", user)
+	}
+
+	raw, err := view.Field("category").Bytes()
+	if err != nil || len(raw) != 4 {
+		t.Errorf("View bytes of category fail, got: %v, err: %+v", raw, err)
+	}
+
+	// array schema
+	sliceView := jsonPack.GetSchema("sliceObject").View(testdata.SliceExpData)
+	num, err := sliceView.Index(1).Field("num2").Float()
+	if err != nil || num != 2.56 {
+		t.Errorf("View [1].num2 fail, got: %f, err: %+v", num, err)
+	}
+
+	// errors are kept in chained views
+	bad := view.Field("accounts").Index(100).Field("name")
+	if !errors.As(bad.Err(), &expectErr) || expectErr.Path != "accounts[100]" {
+		t.Errorf("View out of range should fail, err: %v", bad.Err())
+	}
+	if _, err = bad.String(); !errors.As(err, &expectErr) {
+		t.Errorf("View out of range should fail, err: %v", err)
+	}
+	if _, err = view.Field("unknown").Int(); !errors.As(err, &expectErr) {
+		t.Errorf("View unknown property should fail, err: %v", err)
+	}
+	if _, err = view.Field("category").Field("name").Int(); !errors.As(err, &expectErr) {
+		t.Errorf("View property of number should fail, err: %v", err)
+	}
+	var typeErr *WrongTypeError
+	if _, err = view.Field("category").String(); !errors.As(err, &typeErr) {
+		t.Errorf("View string of number should fail, err: %v", err)
+	}
+
+	// truncated data
+	truncated := jsonPack.GetSchema("complex").View(testdata.ComplexExpData[:20])
+	if _, err = truncated.Field("accounts").Len(); !errors.As(err, &expectErr) {
+		t.Errorf("View truncated data should fail, err: %v", err)
+	}
+	truncated = jsonPack.GetSchema("sliceObject").View(testdata.SliceExpData[:len(testdata.SliceExpData)-1])
+	if _, err = truncated.Index(2).Field("obj").Field("name").Len(); !errors.As(err, &expectErr) {
+		t.Errorf("View length of truncated string should fail, err: %v", err)
+	}
+
+	// the length of fixed-size items is larger than the remaining bytes
+	sch, err := NewJSONPack().AddSchema("uint32Items", SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"xs": {Type: "array", Items: &SchemaDef{Type: "uint32le"}}},
+		Order:      []string{"xs"},
+	})
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	if _, err = sch.View([]byte{3, 1, 2, 3, 4}).Field("xs").Index(2).Bytes(); !errors.As(err, &expectErr) {
+		t.Errorf("View bytes of truncated fixed-size item should fail, err: %v", err)
+	}
+}
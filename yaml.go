@@ -0,0 +1,76 @@
+package jsonpack
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+LoadSchemaYAML parses YAML document data into schema definition, the structure of document is
+the same as JSON schema definition, and the returned schema definition can be added by AddSchema method.
+
+The comments, anchors and aliases of YAML can be used to document and reuse parts of schema definition.
+
+AddSchema method doesn't accept YAML text, the string and []byte arguments of it are always parsed as JSON,
+so YAML document needs to be loaded by LoadSchemaYAML function first.
+
+It returns *ConvertError error if data isn't a valid YAML document of schema definition.
+
+Example:
+	# info.yaml
+	type: object
+	properties:
+	  name: {type: string}
+	  home: &address
+	    type: object
+	    properties:
+	      street: {type: string}
+	    order: [street]
+	  office: *address   # reuses the definition of home
+	order: [name, home, office]
+
+	schDef, err := jsonpack.LoadSchemaYAML(data)
+	sch, err := jsonPack.AddSchema("Info", *schDef)
+*/
+func LoadSchemaYAML(data []byte) (*SchemaDef, error) {
+	schDef := SchemaDef{}
+	err := yaml.Unmarshal(data, &schDef)
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	return &schDef, nil
+}
+
+// GetSchemaDefYAML returns YAML document of schema definition, it returns *ConvertError error
+// if schema definition can't be converted to YAML.
+func (s *Schema) GetSchemaDefYAML() ([]byte, error) {
+	schDef, err := s.GetSchemaDef()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	err = enc.Encode(schDef)
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, errors.WithStack(&ConvertError{"", err})
+	}
+	return b.Bytes(), nil
+}
+
+// GetSchemaDefYAML is a wrapper of Schema.GetSchemaDefYAML,
+// it returns *SchemaNonExistError error if schema not found.
+func (p *JSONPack) GetSchemaDefYAML(schemaName string) ([]byte, error) {
+	schema := p.schemaManager.get(schemaName)
+	if schema == nil {
+		return nil, errors.WithStack(&SchemaNonExistError{schemaName})
+	}
+	return schema.GetSchemaDefYAML()
+}
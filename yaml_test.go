@@ -0,0 +1,86 @@
+package jsonpack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/arloliu/jsonpack/testdata"
+)
+
+func TestLoadSchemaYAML(t *testing.T) {
+	data := []byte(`# user information
+type: object
+properties:
+  name: {type: string}
+  area:
+    type: uint32le
+    optional: true
+  home: &address
+    type: object
+    properties:
+      street: {type: string}
+    order: [street]
+  office: *address # reuses the definition of home
+order: [name, area, home, office]
+`)
+
+	schDef, err := LoadSchemaYAML(data)
+	if err != nil {
+		t.Fatalf("LoadSchemaYAML fail, err: %+v", err)
+	}
+	addrDef := &SchemaDef{
+		Type:       "object",
+		Properties: map[string]*SchemaDef{"street": {Type: "string"}},
+		Order:      []string{"street"},
+	}
+	expDef := &SchemaDef{
+		Type: "object",
+		Properties: map[string]*SchemaDef{
+			"name":   {Type: "string"},
+			"area":   {Type: "uint32le", Optional: true},
+			"home":   addrDef,
+			"office": addrDef,
+		},
+		Order: []string{"name", "area", "home", "office"},
+	}
+	if !reflect.DeepEqual(schDef, expDef) {
+		t.Errorf("LoadSchemaYAML result: %+v, expect: %+v", schDef, expDef)
+	}
+
+	if _, err = NewJSONPack().AddSchema("yaml", *schDef); err != nil {
+		t.Errorf("AddSchema with YAML schema definition fail, err: %+v", err)
+	}
+
+	var convertErr *ConvertError
+	if _, err = LoadSchemaYAML([]byte("type: [object")); !errors.As(err, &convertErr) {
+		t.Errorf("LoadSchemaYAML with invalid document should return ConvertError, err: %v", err)
+	}
+}
+
+func TestGetSchemaDefYAML(t *testing.T) {
+	data, err := jsonPack.GetSchemaDefYAML("complex")
+	if err != nil {
+		t.Fatalf("GetSchemaDefYAML fail, err: %+v", err)
+	}
+
+	schDef, err := LoadSchemaYAML(data)
+	if err != nil {
+		t.Fatalf("LoadSchemaYAML fail, err: %+v\n%s", err, data)
+	}
+	sch, err := NewJSONPack().AddSchema("complex", *schDef)
+	if err != nil {
+		t.Fatalf("AddSchema fail, err: %+v", err)
+	}
+	result, err := sch.Encode(testdata.ComplexData)
+	if err != nil {
+		t.Fatalf("Encode fail, err: %+v", err)
+	}
+	compareBytes(t, testdata.ComplexExpData, result)
+
+	var nonExistErr *SchemaNonExistError
+	if _, err = jsonPack.GetSchemaDefYAML("nonExist"); !errors.As(err, &nonExistErr) {
+		t.Errorf("GetSchemaDefYAML of non-exist schema should fail, err: %v", err)
+	}
+}